@@ -1,22 +1,34 @@
 package dt1
 
 import (
+	"io"
+
 	"github.com/gravestench/dt1/pkg"
 )
 
 type (
-	DT1             = pkg.DT1
-	Tile            = pkg.Tile
-	Block           = pkg.Block
-	MaterialFlags   = pkg.MaterialFlags
-	SubTileFlags    = pkg.SubTileFlags
-	BlockDataFormat = pkg.BlockDataFormat
+	DT1              = pkg.DT1
+	Tile             = pkg.Tile
+	Block            = pkg.Block
+	MaterialFlags    = pkg.MaterialFlags
+	SubTileFlags     = pkg.SubTileFlags
+	BlockDataFormat  = pkg.BlockDataFormat
+	MemoryUsage      = pkg.MemoryUsage
+	CorruptionReport = pkg.CorruptionReport
+	DT1Diff          = pkg.DT1Diff
+	TileDiff         = pkg.TileDiff
+	AnimatedGroup    = pkg.AnimatedGroup
+	AnimationKey     = pkg.AnimationKey
 )
 
 func FromBytes(fileData []byte) (result *DT1, err error) {
 	return pkg.FromBytes(fileData)
 }
 
+func FromFile(path string) (*DT1, error) {
+	return pkg.FromFile(path)
+}
+
 func NewSubTileFlags(data byte) SubTileFlags {
 	return pkg.NewSubTileFlags(data)
 }
@@ -24,3 +36,7 @@ func NewSubTileFlags(data byte) SubTileFlags {
 func NewMaterialFlags(data uint16) MaterialFlags {
 	return pkg.NewMaterialFlags(data)
 }
+
+func LoadFromJSON(r io.Reader) (*DT1, error) {
+	return pkg.LoadFromJSON(r)
+}
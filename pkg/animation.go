@@ -0,0 +1,183 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"sort"
+)
+
+// GroupByAnimation groups the tiles by their Type, Style and Sequence, which
+// is how animation frames are identified: tiles in the same group with a
+// different RarityFrameIndex are frames of the same animation. Each slice in
+// the returned map is ordered by RarityFrameIndex. Tiles which are not
+// animated form a group of their own.
+func (d *DT1) GroupByAnimation() map[AnimationKey][]*Tile {
+	groups := make(map[AnimationKey][]*Tile)
+
+	for _, tile := range d.Tiles {
+		key := tile.animationKey()
+		groups[key] = append(groups[key], tile)
+	}
+
+	for _, frames := range groups {
+		sort.SliceStable(frames, func(i, j int) bool {
+			return frames[i].RarityFrameIndex < frames[j].RarityFrameIndex
+		})
+	}
+
+	return groups
+}
+
+// IsAnimated returns true if another tile in the given slice has the same
+// Type, Style and Sequence as this tile, but a different RarityFrameIndex.
+func (t *Tile) IsAnimated(allTiles []*Tile) bool {
+	for _, other := range allTiles {
+		if other == t || !t.sameAnimationGroup(other) {
+			continue
+		}
+
+		if other.RarityFrameIndex != t.RarityFrameIndex {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (t *Tile) sameAnimationGroup(other *Tile) bool {
+	return t.Type == other.Type && t.Style == other.Style && t.Sequence == other.Sequence
+}
+
+// AnimatedGroup is a set of tiles with the same Type, Style and Sequence,
+// which are the frames of one animation.
+type AnimatedGroup struct {
+	Type     int32
+	Style    int32
+	Sequence int32
+	Frames   []*Tile // ordered by RarityFrameIndex
+
+	rendered map[int]renderedFrame
+}
+
+// renderedFrame is a frame of an AnimatedGroup rendered with a palette
+type renderedFrame struct {
+	palette color.Palette
+	image   image.Image
+}
+
+// AnimatedGroups returns the groups of tiles which form animations, that is
+// every Type, Style and Sequence shared by more than one tile. Groups are in
+// the order their first tile appears in the file.
+func (d *DT1) AnimatedGroups() []AnimatedGroup {
+	frames := d.GroupByAnimation()
+	groups := make([]AnimatedGroup, 0)
+
+	for _, tile := range d.Tiles {
+		key := tile.animationKey()
+
+		// groups which were already added are set to nil below, so only the
+		// first tile of each animated group adds it
+		if len(frames[key]) < 2 {
+			continue
+		}
+
+		groups = append(groups, AnimatedGroup{
+			Type:     key.Type,
+			Style:    key.Style,
+			Sequence: key.Sequence,
+			Frames:   frames[key],
+			rendered: make(map[int]renderedFrame),
+		})
+
+		frames[key] = nil
+	}
+
+	return groups
+}
+
+// ImageAt renders the frame at frameIndex with the given palette, which must
+// have 256 colors. Palette index 0 is rendered as transparent. Rendered frames
+// are cached, and reused as long as they are requested with an equal palette.
+func (g *AnimatedGroup) ImageAt(frameIndex int, palette color.Palette) (image.Image, error) {
+	const numColors = 256
+
+	if frameIndex < 0 || frameIndex >= len(g.Frames) {
+		const fmtErr = "frame index %d is out of range, the group has %d frames"
+		return nil, fmt.Errorf(fmtErr, frameIndex, len(g.Frames))
+	}
+
+	if len(palette) < numColors {
+		return nil, errors.New("palette must have 256 colors")
+	}
+
+	if cached, found := g.rendered[frameIndex]; found && samePalette(cached.palette, palette) {
+		return cached.image, nil
+	}
+
+	tile := g.Frames[frameIndex]
+
+	framePalette := append(color.Palette(nil), palette[:numColors]...)
+	framePalette[0] = color.Transparent
+
+	img := &image.Paletted{
+		Pix:     tile.PaletteIndices(),
+		Stride:  int(tile.Width),
+		Rect:    image.Rect(0, 0, int(tile.Width), tile.WallHeight()),
+		Palette: framePalette,
+	}
+
+	if g.rendered == nil {
+		g.rendered = make(map[int]renderedFrame)
+	}
+
+	g.rendered[frameIndex] = renderedFrame{
+		palette: append(color.Palette(nil), palette...),
+		image:   img,
+	}
+
+	return img, nil
+}
+
+func samePalette(a, b color.Palette) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for idx := range a {
+		if a[idx] != b[idx] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// AnimationKey identifies the group of tiles which form one animation
+type AnimationKey struct {
+	Type     int32
+	Style    int32
+	Sequence int32
+}
+
+func (t *Tile) animationKey() AnimationKey {
+	return AnimationKey{Type: t.Type, Style: t.Style, Sequence: t.Sequence}
+}
+
+// HasAnimatedTiles returns true if any two tiles share the same Type, Style
+// and Sequence, meaning they are frames of the same animation.
+func (d *DT1) HasAnimatedTiles() bool {
+	seen := make(map[AnimationKey]struct{}, len(d.Tiles))
+
+	for _, tile := range d.Tiles {
+		key := tile.animationKey()
+		if _, found := seen[key]; found {
+			return true
+		}
+
+		seen[key] = struct{}{}
+	}
+
+	return false
+}
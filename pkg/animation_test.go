@@ -0,0 +1,110 @@
+package pkg
+
+import (
+	"image/color"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+func TestAnimatedGroups(t *testing.T) {
+	assert := testify.New(t)
+
+	d := testEncodableDT1()
+	assert.False(d.HasAnimatedTiles())
+	assert.Empty(d.AnimatedGroups())
+
+	frame := d.Tiles[0].clone(d)
+	frame.RarityFrameIndex = 1
+	d.Tiles = append(d.Tiles, frame)
+
+	assert.True(d.HasAnimatedTiles())
+
+	groups := d.AnimatedGroups()
+	if !assert.Len(groups, 1) {
+		return
+	}
+
+	group := &groups[0]
+	assert.Equal([]*Tile{frame, d.Tiles[0]}, group.Frames)
+
+	palette := make(color.Palette, 256)
+	for idx := range palette {
+		palette[idx] = color.RGBA{R: uint8(idx), A: 255}
+	}
+
+	img, err := group.ImageAt(1, palette)
+	assert.NoError(err)
+	assert.Equal(color.RGBA{R: 5, A: 255}, img.At(16, 32))
+	assert.Equal(color.Transparent, img.At(0, 0))
+
+	cached, err := group.ImageAt(1, palette)
+	assert.NoError(err)
+	assert.Same(img, cached)
+
+	_, err = group.ImageAt(2, palette)
+	assert.Error(err)
+}
+
+func TestGroupByAnimation(t *testing.T) {
+	assert := testify.New(t)
+
+	d := NewTestDT1(3, 1)
+	d.Tiles[0].Style, d.Tiles[0].Sequence, d.Tiles[0].RarityFrameIndex = 1, 5, 2
+	d.Tiles[1].Style, d.Tiles[1].Sequence, d.Tiles[1].RarityFrameIndex = 2, 5, 0
+	d.Tiles[2].Style, d.Tiles[2].Sequence, d.Tiles[2].RarityFrameIndex = 1, 5, 1
+
+	groups := d.GroupByAnimation()
+	assert.Len(groups, 2)
+
+	assert.Equal([]*Tile{d.Tiles[2], d.Tiles[0]}, groups[AnimationKey{Type: TileTypeFloor, Style: 1, Sequence: 5}])
+	assert.Equal([]*Tile{d.Tiles[1]}, groups[AnimationKey{Type: TileTypeFloor, Style: 2, Sequence: 5}])
+
+	animated := d.AnimatedGroups()
+	if assert.Len(animated, 1) {
+		assert.Equal(int32(1), animated[0].Style)
+		assert.Equal([]*Tile{d.Tiles[2], d.Tiles[0]}, animated[0].Frames)
+	}
+}
+
+func TestHasAnimatedTiles(t *testing.T) {
+	assert := testify.New(t)
+
+	d := NewTestDT1(3, 0)
+	assert.False(d.HasAnimatedTiles(), "every tile has its own style")
+
+	d.Tiles[2].Style = d.Tiles[0].Style
+	d.Tiles[2].Type = TileTypeRoof
+	assert.False(d.HasAnimatedTiles(), "the type differs")
+
+	d.Tiles[2].Type = d.Tiles[0].Type
+	d.Tiles[2].Sequence = 1
+	assert.False(d.HasAnimatedTiles(), "the sequence differs")
+
+	d.Tiles[2].Sequence = d.Tiles[0].Sequence
+	assert.True(d.HasAnimatedTiles())
+
+	assert.False(NewTestDT1(0, 0).HasAnimatedTiles())
+}
+
+func TestAnimatedGroupsOrder(t *testing.T) {
+	assert := testify.New(t)
+
+	d := NewTestDT1(5, 0)
+	d.Tiles[0].Style = 7
+	d.Tiles[1].Style, d.Tiles[1].RarityFrameIndex = 3, 1
+	d.Tiles[3].Style, d.Tiles[3].RarityFrameIndex = 3, 0
+	d.Tiles[4].Style, d.Tiles[4].RarityFrameIndex = 7, 1
+
+	groups := d.AnimatedGroups()
+	if !assert.Len(groups, 2) {
+		return
+	}
+
+	// groups are ordered by their first tile, frames by RarityFrameIndex
+	assert.Equal(int32(7), groups[0].Style)
+	assert.Equal([]*Tile{d.Tiles[0], d.Tiles[4]}, groups[0].Frames)
+	assert.Equal(int32(3), groups[1].Style)
+	assert.Equal([]*Tile{d.Tiles[3], d.Tiles[1]}, groups[1].Frames)
+	assert.Equal(TileTypeFloor, groups[1].Type)
+}
@@ -0,0 +1,68 @@
+package pkg
+
+import (
+	"errors"
+	"image"
+	"image/draw"
+	"image/png"
+	"os"
+)
+
+// TileAtlas renders all tiles into a single image, laid out left to right and
+// top to bottom with tilesPerRow tiles in each row. Every cell of the atlas has
+// the dimensions of the largest tile, see MaxTileDimensions. Tiles which can
+// not be rendered leave their cell transparent.
+func (d *DT1) TileAtlas(tilesPerRow int) (*image.RGBA, error) {
+	if tilesPerRow <= 0 {
+		return nil, errors.New("tiles per row must be positive")
+	}
+
+	if len(d.Tiles) == 0 {
+		return nil, errors.New("DT1 has no tiles")
+	}
+
+	cellW, cellH := d.MaxTileDimensions()
+	numRows := (len(d.Tiles) + tilesPerRow - 1) / tilesPerRow
+	numCols := tilesPerRow
+
+	if len(d.Tiles) < tilesPerRow {
+		numCols = len(d.Tiles)
+	}
+
+	atlas := image.NewRGBA(image.Rect(0, 0, numCols*cellW, numRows*cellH))
+
+	for idx, tile := range d.Tiles {
+		img := tile.Image()
+		if img == nil {
+			continue
+		}
+
+		x, y := (idx%tilesPerRow)*cellW, (idx/tilesPerRow)*cellH
+		cell := image.Rect(x, y, x+cellW, y+cellH)
+
+		draw.Draw(atlas, cell, img, img.Bounds().Min, draw.Over)
+	}
+
+	return atlas, nil
+}
+
+// ExportTileAtlasToPNG renders the tile atlas, see TileAtlas, and writes it to
+// the file at the given path as a PNG.
+func (d *DT1) ExportTileAtlasToPNG(path string, tilesPerRow int) error {
+	atlas, err := d.TileAtlas(tilesPerRow)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := png.Encode(file, atlas); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
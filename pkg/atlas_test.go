@@ -0,0 +1,40 @@
+package pkg
+
+import (
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+func TestExportTileAtlasToPNG(t *testing.T) {
+	assert := testify.New(t)
+
+	d := NewTestDT1(3, 1)
+	path := filepath.Join(t.TempDir(), "atlas.png")
+
+	assert.NoError(d.ExportTileAtlasToPNG(path, 2))
+
+	file, err := os.Open(path)
+	if !assert.NoError(err) {
+		return
+	}
+
+	defer file.Close()
+
+	img, err := png.Decode(file)
+	assert.NoError(err)
+	assert.Equal(image.Rect(0, 0, 2*160, 2*80), img.Bounds())
+
+	// the third tile starts the second row
+	_, _, _, a := img.At(14, 80).RGBA()
+	assert.NotZero(a)
+	_, _, _, a = img.At(160+14, 80).RGBA()
+	assert.Zero(a)
+
+	assert.Error(d.ExportTileAtlasToPNG(path, 0))
+	assert.Error(d.ExportTileAtlasToPNG(filepath.Join(t.TempDir(), "missing", "atlas.png"), 2))
+}
@@ -0,0 +1,96 @@
+package pkg
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+// benchmarkFile is generated by newBenchmarkDT1: floor tiles of 25 isometric
+// blocks, a roof tile, and RLE wall and shadow tiles of differing heights.
+// Regenerate it with go test -run TestBenchmarkFileIsGenerated -update
+const benchmarkFile = "testdata/tiles.dt1"
+
+var updateBenchmarkFile = flag.Bool("update", false, "regenerate "+benchmarkFile)
+
+func TestBenchmarkFileIsGenerated(t *testing.T) {
+	assert := testify.New(t)
+
+	generated, err := newBenchmarkDT1().ToBytes()
+	if !assert.NoError(err) {
+		return
+	}
+
+	if *updateBenchmarkFile {
+		assert.NoError(os.WriteFile(benchmarkFile, generated, 0o644))
+	}
+
+	data, err := os.ReadFile(benchmarkFile)
+	assert.NoError(err)
+	assert.True(bytes.Equal(generated, data), "%s is out of date, regenerate it with -update", benchmarkFile)
+}
+
+func loadBenchmarkFile(b *testing.B) []byte {
+	data, err := os.ReadFile(benchmarkFile)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	return data
+}
+
+func BenchmarkFromBytes(b *testing.B) {
+	data := loadBenchmarkFile(b)
+
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := FromBytes(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeAllGraphics(b *testing.B) {
+	data := loadBenchmarkFile(b)
+
+	d, err := FromBytes(data)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.SetBytes(d.TotalEncodedBytes())
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := d.DecodeAllGraphics(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkTileAtlas(b *testing.B) {
+	data := loadBenchmarkFile(b)
+
+	d, err := FromBytes(data)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	if err := d.DecodeAllGraphics(); err != nil {
+		b.Fatal(err)
+	}
+
+	b.SetBytes(d.TotalTileArea())
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := d.TileAtlas(8); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
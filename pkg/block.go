@@ -1,12 +1,20 @@
 package pkg
 
 import (
+	"errors"
+	"fmt"
 	"image"
 	"image/color"
 )
 
 var _ image.PalettedImage = &Block{}
 
+const (
+	blockWidth           = 32
+	blockHeightIsometric = 16
+	blockHeightRLE       = 32
+)
+
 // Block represents a DT1 block
 type Block struct {
 	tile        *Tile
@@ -20,6 +28,7 @@ type Block struct {
 	FileOffset  int32
 	PixelData   []byte
 	image       *image.RGBA
+	stale       bool // PixelData no longer matches the position or EncodedData
 }
 
 func (block *Block) ColorIndexAt(x, y int) uint8 {
@@ -56,3 +65,317 @@ func (block *Block) At(x, y int) color.Color {
 func (b *Block) Format() BlockDataFormat {
 	return b.format
 }
+
+// Recolor returns a copy of the block with its pixel data remapped through
+// the given table, where remapTable[i] is the new palette index for pixels
+// which currently have palette index i. Index 0 (transparent) is always
+// mapped to 0. The original block is not modified.
+func (block *Block) Recolor(remapTable [256]uint8) *Block {
+	remapTable[0] = 0
+
+	recolored := *block
+
+	if block.PixelData != nil {
+		recolored.PixelData = make([]byte, len(block.PixelData))
+
+		for idx, palIdx := range block.PixelData {
+			recolored.PixelData[idx] = remapTable[palIdx]
+		}
+	}
+
+	if block.EncodedData != nil {
+		recolored.EncodedData = append([]byte{}, block.EncodedData...)
+		recolored.remapEncodedData(remapTable)
+	}
+
+	return &recolored
+}
+
+// remapEncodedData remaps the palette indices inside of the encoded data,
+// leaving the run-length headers of RLE blocks untouched.
+func (block *Block) remapEncodedData(remapTable [256]uint8) {
+	if block.format == BlockFormatIsometric {
+		for idx, palIdx := range block.EncodedData {
+			block.EncodedData[idx] = remapTable[palIdx]
+		}
+
+		return
+	}
+
+	for idx := 0; idx+1 < len(block.EncodedData); {
+		runLength := int(block.EncodedData[idx+1])
+		idx += 2
+
+		for ; runLength > 0 && idx < len(block.EncodedData); runLength-- {
+			block.EncodedData[idx] = remapTable[block.EncodedData[idx]]
+			idx++
+		}
+	}
+}
+
+// ExpandToFullTile returns a buffer of palette indices with the given
+// dimensions, in which the block's pixels are placed at their position within
+// the tile and all other pixels are 0. This is the inverse of extracting a
+// block from a tile, and is used to stitch blocks back into a tile buffer. The
+// block is decoded first if needed, and an error is returned if it can not be
+// decoded.
+func (block *Block) ExpandToFullTile(tileWidth, tileHeight int) ([]byte, error) {
+	if err := block.ensureDecoded(); err != nil {
+		return nil, err
+	}
+
+	result := make([]byte, tileWidth*tileHeight)
+
+	stride := block.Stride()
+	if block.tile == nil {
+		stride = tileWidth
+	}
+
+	if stride <= 0 {
+		return result, nil
+	}
+
+	for idx, palIdx := range block.PixelData {
+		x, y := idx%stride, idx/stride
+
+		if palIdx == 0 || x >= tileWidth || y >= tileHeight {
+			continue
+		}
+
+		result[y*tileWidth+x] = palIdx
+	}
+
+	return result, nil
+}
+
+// ToGrayscale converts the block image to grayscale, using the standard
+// luminance weights (0.299R + 0.587G + 0.114B). The block is decoded first if
+// it has not been decoded yet, and an error is returned if it can not be
+// decoded.
+func (block *Block) ToGrayscale() (image.Image, error) {
+	if err := block.ensureDecoded(); err != nil {
+		return nil, err
+	}
+
+	if block.image == nil {
+		return image.NewGray(image.Rectangle{}), nil
+	}
+
+	bounds := block.Bounds()
+	gray := image.NewGray(bounds)
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, _ := block.At(x, y).RGBA()
+			lum := 0.299*float64(r>>8) + 0.587*float64(g>>8) + 0.114*float64(b>>8)
+			gray.SetGray(x, y, color.Gray{Y: uint8(lum + 0.5)})
+		}
+	}
+
+	return gray, nil
+}
+
+// Size returns the logical size of the block. Isometric blocks are always
+// one isometric diamond, 32 pixels wide and 16 pixels tall. The size of RLE
+// blocks is the extent of the pixels written while decoding the block.
+func (block *Block) Size() (width, height int) {
+	if block.format == BlockFormatIsometric {
+		return blockWidth, blockHeightIsometric
+	}
+
+	return block.rleExtent()
+}
+
+// rleExtent walks the RLE encoded data and returns the extent of the pixel runs
+func (block *Block) rleExtent() (width, height int) {
+	x, y := 0, 0
+
+	for idx := 0; idx+1 < len(block.EncodedData); {
+		xJump, runLength := int(block.EncodedData[idx]), int(block.EncodedData[idx+1])
+		idx += 2
+
+		if (xJump | runLength) == 0 {
+			x = 0
+			y++
+
+			continue
+		}
+
+		x += xJump + runLength
+		idx += runLength
+
+		width = maxInt(width, x)
+		height = y + 1
+	}
+
+	return width, height
+}
+
+// RLEEfficiency returns the ratio of non-zero pixels to the total number of
+// encoded bytes of an RLE block. Values near 1.0 mean the encoding is nearly
+// uncompressed, values near 0.0 mean the data is dominated by skip runs.
+// Isometric blocks and blocks without encoded data yield 0.
+func (block *Block) RLEEfficiency() float64 {
+	if block.format != BlockFormatRLE || len(block.EncodedData) == 0 {
+		return 0
+	}
+
+	opaque := 0
+
+	_ = block.walkPixels(func(_, _ int32, dataIdx int) error {
+		if block.EncodedData[dataIdx] != 0 {
+			opaque++
+		}
+
+		return nil
+	})
+
+	return float64(opaque) / float64(len(block.EncodedData))
+}
+
+// ShiftPosition moves the block by (dx, dy) within its tile. The new position
+// is written with the block headers by ToBytes, and the decoded pixel data of
+// the tile is marked as stale so it is decoded again when it is next used. An
+// error is returned if the block would no longer fit within the tile.
+func (block *Block) ShiftPosition(dx, dy int16) error {
+	if err := block.checkShift(dx, dy); err != nil {
+		return err
+	}
+
+	yOffset := block.tile.yOffset()
+
+	block.X += dx
+	block.Y += dy
+	block.stale = true
+
+	// moving a block can change the vertical offset of the whole tile
+	if block.tile.yOffset() != yOffset {
+		for _, other := range block.tile.Blocks {
+			other.stale = true
+		}
+	}
+
+	return nil
+}
+
+// checkShift verifies that the block still fits within its tile after being
+// moved by (dx, dy).
+func (block *Block) checkShift(dx, dy int16) error {
+	if block.tile == nil {
+		return errors.New("block does not belong to a tile")
+	}
+
+	return block.tile.checkShift([]*Block{block}, dx, dy)
+}
+
+// PaletteIndexHistogram counts the occurrences of each palette index in the
+// block's PixelData, including index 0.
+func (block *Block) PaletteIndexHistogram() [256]int {
+	var histogram [256]int
+
+	for _, palIdx := range block.PixelData {
+		histogram[palIdx]++
+	}
+
+	return histogram
+}
+
+// IsEmpty returns true if every pixel of the block is transparent (index 0).
+// The block is decoded first if needed; a block which can not be decoded is
+// not considered empty.
+func (block *Block) IsEmpty() bool {
+	if err := block.ensureDecoded(); err != nil {
+		return false
+	}
+
+	for _, palIdx := range block.PixelData {
+		if palIdx != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// GridCellBounds returns the pixel rectangle covered by the block's grid cell
+// (GridX, GridY), given the dimensions of the tile. The tile is divided into a
+// 5x5 grid, so for a 160x80 tile each cell is 32x16 pixels.
+func (block *Block) GridCellBounds(tileWidth, tileHeight int) image.Rectangle {
+	cellW, cellH := tileWidth/gridDivisionsXY, tileHeight/gridDivisionsXY
+	x, y := int(block.GridX)*cellW, int(block.GridY)*cellH
+
+	return image.Rect(x, y, x+cellW, y+cellH)
+}
+
+// Stride returns the number of bytes per row of PixelData, which is the width
+// of the parent tile. Returns 0 if the block does not belong to a tile.
+func (block *Block) Stride() int {
+	if block.tile == nil {
+		return 0
+	}
+
+	return int(block.tile.Width)
+}
+
+// OriginPoint returns the top-left origin of the block in tile space
+func (block *Block) OriginPoint() image.Point {
+	return image.Point{X: int(block.X), Y: int(block.Y)}
+}
+
+// NumPixels returns the length of the block's PixelData, which is 0 if the
+// block has not been decoded yet. Note that PixelData spans the whole tile, so
+// this is the tile area rather than the number of pixels the block paints.
+func (block *Block) NumPixels() int {
+	return len(block.PixelData)
+}
+
+// SetEncodedData replaces the block's EncodedData and updates Length. The
+// decoded PixelData is marked as stale, so the block is decoded again when it
+// is next used. Isometric blocks must be given exactly 256 bytes. If the
+// length changes, call DT1.NormalizeBlockOffsets before ToBytes.
+func (block *Block) SetEncodedData(data []byte) error {
+	if len(data) == 0 {
+		return errors.New("encoded data is empty")
+	}
+
+	if block.format == BlockFormatIsometric && len(data) != blockDataLength {
+		const fmtErr = "isometric block data must be %d bytes, got %d"
+		return fmt.Errorf(fmtErr, blockDataLength, len(data))
+	}
+
+	block.EncodedData = data
+	block.Length = int32(len(data))
+	block.stale = true
+
+	return nil
+}
+
+// RowPixels returns row y of the block's PixelData, as palette indices. Rows
+// span the full width of the parent tile, and y ranges over the absolute
+// height of the tile. The block is decoded first if needed.
+//
+// The returned slice is not a copy, it aliases PixelData. It must not be
+// modified, as changes would not be reflected in EncodedData.
+func (block *Block) RowPixels(y int) ([]uint8, error) {
+	if err := block.ensureDecoded(); err != nil {
+		return nil, err
+	}
+
+	width := block.Stride()
+	if width <= 0 {
+		return nil, errors.New("block has a zero width tile")
+	}
+
+	height := len(block.PixelData) / width
+	if y < 0 || y >= height {
+		return nil, fmt.Errorf("row %d is out of range, the block has %d rows", y, height)
+	}
+
+	return block.PixelData[y*width : (y+1)*width], nil
+}
+
+// ParentTile returns the tile the block belongs to, or nil if it does not
+// belong to a tile.
+func (block *Block) ParentTile() *Tile {
+	return block.tile
+}
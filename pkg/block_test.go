@@ -0,0 +1,311 @@
+package pkg
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+func TestBlockRecolor(t *testing.T) {
+	assert := testify.New(t)
+
+	var remap [256]uint8
+	for i := range remap {
+		remap[i] = uint8(255 - i)
+	}
+
+	block := &Block{
+		format:      BlockFormatRLE,
+		EncodedData: []byte{1, 2, 10, 20, 0, 0},
+		PixelData:   []byte{0, 10, 20},
+	}
+
+	recolored := block.Recolor(remap)
+
+	assert.Equal([]byte{0, 245, 235}, recolored.PixelData)
+	assert.Equal([]byte{1, 2, 245, 235, 0, 0}, recolored.EncodedData)
+	assert.Equal([]byte{0, 10, 20}, block.PixelData, "original must not change")
+}
+
+func TestBlockValidateOffsets(t *testing.T) {
+	assert := testify.New(t)
+
+	tile := &Tile{Width: 160, Height: 80}
+	block := &Block{tile: tile, X: 144, format: BlockFormatIsometric, EncodedData: make([]byte, blockDataLength)}
+	tile.Blocks = []*Block{{tile: tile}, block}
+
+	assert.ErrorContains(block.ValidateOffsets(tile.Width, tile.Height), "block 1")
+
+	block.X = 128
+	assert.NoError(block.ValidateOffsets(tile.Width, tile.Height))
+
+	block.EncodedData = block.EncodedData[:10]
+	assert.ErrorContains(block.ValidateOffsets(tile.Width, tile.Height), "truncated")
+}
+
+func TestBlockSetEncodedData(t *testing.T) {
+	assert := testify.New(t)
+
+	block := testEncodableDT1().Tiles[0].Blocks[1]
+	assert.NoError(block.ensureDecoded())
+
+	assert.Error(block.SetEncodedData(nil))
+	assert.Error(block.SetEncodedData(make([]byte, 10)))
+
+	assert.NoError(block.SetEncodedData(bytes.Repeat([]byte{9}, blockDataLength)))
+	assert.Equal(int32(blockDataLength), block.Length)
+	assert.NoError(block.ensureDecoded())
+	assert.Equal(uint8(9), block.PixelData[32*160+16])
+}
+
+func TestBlockShiftPosition(t *testing.T) {
+	assert := testify.New(t)
+
+	tile := testEncodableDT1().Tiles[0]
+	wall := tile.Blocks[0]
+
+	assert.NoError(wall.ShiftPosition(0, 0))
+	assert.NoError(wall.ShiftPosition(4, 2))
+	assert.Equal(int16(20), wall.X)
+	assert.Equal(int16(-30), wall.Y)
+
+	assert.Error(wall.ShiftPosition(200, 0))
+	assert.Equal(int16(20), wall.X, "a failed shift must not move the block")
+
+	d, err := FromFile("testdata/tiles.dt1")
+	assert.NoError(err)
+
+	for _, tile := range d.Tiles {
+		for _, block := range tile.Blocks {
+			assert.NoError(block.ShiftPosition(0, 0))
+		}
+	}
+}
+
+func TestBlockDecoders(t *testing.T) {
+	assert := testify.New(t)
+
+	tile := testEncodableDT1().Tiles[0]
+	rle, isometric := tile.Blocks[0], tile.Blocks[1]
+	width := int(tile.Width)
+
+	assert.NoError(rle.DecodeRLE())
+	assert.Equal(uint8(7), rle.PixelData[17])
+	assert.Equal(uint8(8), rle.PixelData[18])
+	assert.Zero(rle.PixelData[16])
+
+	assert.NoError(isometric.DecodeIsometric())
+	assert.Equal(uint8(5), isometric.PixelData[32*width+16])
+	assert.Zero(isometric.PixelData[32*width])
+
+	// decoding with the wrong decoder must not change the block
+	assert.Error(isometric.DecodeRLE())
+	assert.Equal(BlockFormatIsometric, isometric.Format())
+	assert.Error(rle.DecodeIsometric())
+	assert.Equal(BlockFormatRLE, rle.Format())
+
+	data, err := tile.dt1.ToBytes()
+	assert.NoError(err)
+
+	decoded, err := FromBytes(data)
+	assert.NoError(err)
+	assert.True(decoded.Tiles[0].Equals(tile))
+}
+
+func TestBlockExpandToFullTile(t *testing.T) {
+	assert := testify.New(t)
+
+	tile := NewTestDT1(1, 2).Tiles[0]
+	block := tile.Blocks[1]
+
+	expanded, err := block.ExpandToFullTile(160, 80)
+	assert.NoError(err)
+	assert.Len(expanded, 160*80)
+	assert.Equal(block.EncodedData[0], expanded[blockWidth+14])
+	assert.Zero(expanded[14], "pixels of other blocks must stay transparent")
+
+	// the block is at the top of the tile, so cropping the height keeps it
+	cropped, err := block.ExpandToFullTile(160, 16)
+	assert.NoError(err)
+	assert.Equal(expanded[:160*16], cropped)
+
+	detached := &Block{format: BlockFormatIsometric, EncodedData: block.EncodedData}
+	_, err = detached.ExpandToFullTile(160, 80)
+	assert.Error(err)
+}
+
+func TestBlockToGrayscale(t *testing.T) {
+	assert := testify.New(t)
+
+	d := NewTestDT1(1, 1)
+	palette := make(color.Palette, 256)
+
+	for idx := range palette {
+		palette[idx] = color.RGBA{A: 255}
+	}
+
+	palette[1] = color.RGBA{R: 255, A: 255}
+	palette[2] = color.RGBA{B: 255, A: 255}
+	d.SetPalette(palette)
+
+	img, err := d.Tiles[0].Blocks[0].ToGrayscale()
+	assert.NoError(err)
+	assert.Equal(image.Rect(0, 0, 160, 80), img.Bounds())
+
+	assert.Equal(color.Gray{Y: 76}, img.At(14, 0), "0.299 * 255")
+	assert.Equal(color.Gray{Y: 29}, img.At(15, 0), "0.114 * 255")
+	assert.Equal(color.Gray{Y: 0}, img.At(0, 0))
+
+	palette[1] = color.RGBA{G: 255, A: 255}
+	img, err = d.Tiles[0].Blocks[0].ToGrayscale()
+	assert.NoError(err)
+	assert.Equal(color.Gray{Y: 150}, img.At(14, 0), "0.587 * 255")
+
+	detached := &Block{format: BlockFormatIsometric}
+	_, err = detached.ToGrayscale()
+	assert.Error(err)
+}
+
+func TestBlockSize(t *testing.T) {
+	assert := testify.New(t)
+
+	tile := testEncodableDT1().Tiles[0]
+	rle, isometric := tile.Blocks[0], tile.Blocks[1]
+
+	w, h := isometric.Size()
+	assert.Equal(32, w)
+	assert.Equal(16, h)
+
+	w, h = rle.Size()
+	assert.Equal(3, w)
+	assert.Equal(1, h)
+
+	// an empty row counts towards the height, the widest row sets the width
+	rle.EncodedData = []byte{1, 2, 7, 8, 0, 0, 0, 0, 4, 1, 9, 0, 0}
+	w, h = rle.Size()
+	assert.Equal(5, w)
+	assert.Equal(3, h)
+
+	rle.EncodedData = nil
+	w, h = rle.Size()
+	assert.Zero(w)
+	assert.Zero(h)
+}
+
+func TestBlockRLEEfficiency(t *testing.T) {
+	assert := testify.New(t)
+
+	tile := testEncodableDT1().Tiles[0]
+	rle, isometric := tile.Blocks[0], tile.Blocks[1]
+
+	assert.InDelta(2.0/6, rle.RLEEfficiency(), 1e-9)
+
+	// transparent pixels within a run do not count
+	rle.EncodedData = []byte{1, 2, 7, 0, 0, 0}
+	assert.InDelta(1.0/6, rle.RLEEfficiency(), 1e-9)
+
+	rle.EncodedData = []byte{}
+	assert.Zero(rle.RLEEfficiency())
+	assert.Zero(isometric.RLEEfficiency())
+}
+
+func TestBlockPaletteIndexHistogram(t *testing.T) {
+	assert := testify.New(t)
+
+	d := NewTestDT1(1, 1)
+	block := d.Tiles[0].Blocks[0]
+
+	assert.Equal([256]int{}, block.PaletteIndexHistogram(), "the block is not decoded yet")
+
+	assert.NoError(d.DecodeAllGraphics())
+
+	histogram := block.PaletteIndexHistogram()
+	assert.Equal(160*80-blockDataLength, histogram[0])
+	assert.Equal(blockDataLength/2, histogram[1])
+	assert.Equal(blockDataLength/2, histogram[2])
+	assert.Zero(histogram[3])
+}
+
+func TestBlockIsEmpty(t *testing.T) {
+	assert := testify.New(t)
+
+	tile := NewTestDT1(1, 2).Tiles[0]
+	assert.False(tile.Blocks[0].IsEmpty())
+
+	empty := tile.Blocks[1]
+	empty.EncodedData = make([]byte, blockDataLength)
+	assert.True(empty.IsEmpty())
+
+	rle := testEncodableDT1().Tiles[0].Blocks[0]
+	rle.EncodedData = []byte{}
+	rle.Length = 0
+	assert.True(rle.IsEmpty())
+
+	// a block which can not be decoded is not empty
+	undecodable := &Block{format: BlockFormatIsometric, EncodedData: make([]byte, blockDataLength)}
+	assert.False(undecodable.IsEmpty())
+}
+
+func TestBlockStride(t *testing.T) {
+	assert := testify.New(t)
+
+	tile := testEncodableDT1().Tiles[0]
+	block := tile.Blocks[1]
+
+	assert.Equal(160, block.Stride())
+
+	// the stride is the row length of PixelData
+	assert.NoError(block.DecodeIsometric())
+	assert.Equal(uint8(5), block.PixelData[(32+5)*block.Stride()+16])
+
+	assert.Zero((&Block{}).Stride())
+}
+
+func TestBlockOriginPoint(t *testing.T) {
+	assert := testify.New(t)
+
+	tile := testEncodableDT1().Tiles[0]
+	assert.Equal(image.Pt(16, -32), tile.Blocks[0].OriginPoint())
+	assert.Equal(image.Pt(0, 0), tile.Blocks[1].OriginPoint())
+
+	assert.NoError(tile.Blocks[0].ShiftPosition(4, 2))
+	assert.Equal(image.Pt(20, -30), tile.Blocks[0].OriginPoint())
+}
+
+func TestBlockNumPixels(t *testing.T) {
+	assert := testify.New(t)
+
+	block := testEncodableDT1().Tiles[0].Blocks[0]
+	assert.Zero(block.NumPixels(), "the block is not decoded yet")
+
+	// PixelData spans the whole tile
+	assert.NoError(block.DecodeRLE())
+	assert.Equal(160*80, block.NumPixels())
+}
+
+func TestBlockRowPixels(t *testing.T) {
+	assert := testify.New(t)
+
+	tile := testEncodableDT1().Tiles[0]
+	rle := tile.Blocks[0]
+
+	row, err := rle.RowPixels(0)
+	assert.NoError(err)
+	assert.Len(row, 160)
+	assert.Equal([]uint8{0, 7, 8, 0}, row[16:20])
+
+	row, err = rle.RowPixels(79)
+	assert.NoError(err)
+	assert.Len(row, 160)
+
+	for _, y := range []int{-1, 80} {
+		_, err = rle.RowPixels(y)
+		assert.Error(err, "row %d", y)
+	}
+
+	_, err = (&Block{format: BlockFormatRLE}).RowPixels(0)
+	assert.Error(err)
+}
@@ -0,0 +1,29 @@
+package pkg
+
+// tileCache holds lookup tables which are derived from the tiles of a DT1.
+// The tables are built lazily and dropped whenever the tiles are mutated.
+type tileCache struct {
+	byDirection map[int32][]*Tile
+	byStyle     map[int32][]*Tile
+	byType      map[int32][]*Tile
+}
+
+// InvalidateCache drops all lookup tables which were derived from the tiles.
+// Methods of the DT1 which mutate the tiles call this automatically, callers
+// that modify the Tiles slice (or the tiles within it) directly must call it
+// themselves.
+func (d *DT1) InvalidateCache() {
+	d.cache = tileCache{}
+}
+
+// copyTileGroups returns a copy of a cached grouping of tiles, so that callers
+// can not modify the cache.
+func copyTileGroups(groups map[int32][]*Tile) map[int32][]*Tile {
+	copied := make(map[int32][]*Tile, len(groups))
+
+	for key, tiles := range groups {
+		copied[key] = append([]*Tile(nil), tiles...)
+	}
+
+	return copied
+}
@@ -0,0 +1,63 @@
+package pkg
+
+import "fmt"
+
+// CorruptionReport describes a problem found by DetectCorruption. BlockIndex
+// is -1 if the problem concerns the tile as a whole.
+type CorruptionReport struct {
+	TileIndex   int
+	BlockIndex  int
+	Description string
+}
+
+func (r CorruptionReport) String() string {
+	if r.BlockIndex < 0 {
+		return fmt.Sprintf("tile %d: %s", r.TileIndex, r.Description)
+	}
+
+	return fmt.Sprintf("tile %d, block %d: %s", r.TileIndex, r.BlockIndex, r.Description)
+}
+
+// DetectCorruption runs a set of heuristic checks over the tiles and blocks,
+// and reports everything that looks corrupt:
+//   - blocks whose data lies beyond the end of the file the DT1 was read from;
+//     FromBytes already rejects such files, so this only finds blocks whose
+//     offsets were changed after loading
+//   - tiles without any blocks
+//   - blocks with a Length of 0, but with encoded data
+//   - tiles with a Type which is not one of the known tile types
+func (d *DT1) DetectCorruption() []CorruptionReport {
+	reports := make([]CorruptionReport, 0)
+
+	report := func(tileIdx, blockIdx int, format string, args ...interface{}) {
+		reports = append(reports, CorruptionReport{
+			TileIndex:   tileIdx,
+			BlockIndex:  blockIdx,
+			Description: fmt.Sprintf(format, args...),
+		})
+	}
+
+	for tileIdx, tile := range d.Tiles {
+		if len(tile.Blocks) == 0 {
+			report(tileIdx, -1, "tile has no blocks")
+		}
+
+		if !IsKnownTileType(tile.Type) {
+			report(tileIdx, -1, "unknown tile type %d", tile.Type)
+		}
+
+		for blockIdx, block := range tile.Blocks {
+			dataEnd := int64(tile.blockHeaderPointer) + int64(block.FileOffset) + int64(block.Length)
+			if d.fileSize > 0 && dataEnd > int64(d.fileSize) {
+				const format = "block data ends at %d, beyond the file size of %d"
+				report(tileIdx, blockIdx, format, dataEnd, d.fileSize)
+			}
+
+			if block.Length == 0 && len(block.EncodedData) > 0 {
+				report(tileIdx, blockIdx, "length is 0, but the block has %d bytes of encoded data", len(block.EncodedData))
+			}
+		}
+	}
+
+	return reports
+}
@@ -0,0 +1,53 @@
+package pkg
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+func TestDetectCorruptionCleanLoad(t *testing.T) {
+	assert := testify.New(t)
+
+	d, err := FromFile("testdata/tiles.dt1")
+	assert.NoError(err)
+	assert.Empty(d.DetectCorruption())
+
+	// a block without any data is valid, and is loaded with empty encoded data
+	d = testEncodableDT1()
+	d.Tiles[0].Blocks[0].EncodedData = []byte{}
+	assert.NoError(d.NormalizeBlockOffsets())
+
+	data, err := d.ToBytes()
+	assert.NoError(err)
+
+	loaded, err := FromBytes(data)
+	assert.NoError(err)
+	assert.Empty(loaded.DetectCorruption())
+}
+
+func TestDetectCorruptionCorruptLoad(t *testing.T) {
+	assert := testify.New(t)
+
+	d := NewTestDT1(2, 1)
+	d.Tiles[0].Type = 99
+	d.Tiles[1].Blocks = nil
+	assert.NoError(d.NormalizeBlockOffsets())
+
+	data, err := d.ToBytes()
+	assert.NoError(err)
+
+	loaded, err := FromBytes(data)
+	assert.NoError(err)
+
+	block := loaded.Tiles[0].Blocks[0]
+	block.Length = 0
+	block.FileOffset = int32(len(data))
+
+	assert.Equal([]CorruptionReport{
+		{TileIndex: 0, BlockIndex: -1, Description: "unknown tile type 99"},
+		{TileIndex: 0, BlockIndex: 0, Description: "block data ends at 1212, beyond the file size of 744"},
+		{TileIndex: 0, BlockIndex: 0, Description: "length is 0, but the block has 256 bytes of encoded data"},
+		{TileIndex: 1, BlockIndex: -1, Description: "tile has no blocks"},
+	}, loaded.DetectCorruption())
+}
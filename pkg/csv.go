@@ -0,0 +1,151 @@
+package pkg
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+var csvHeader = []string{
+	"index",
+	"direction",
+	"roofHeight",
+	"materialFlags",
+	"height",
+	"width",
+	"type",
+	"style",
+	"sequence",
+	"rarityFrameIndex",
+	"blockCount",
+}
+
+// ExportCSV writes the tile metadata to w as CSV, with a header row followed
+// by one row per tile.
+func (d *DT1) ExportCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for idx, tile := range d.Tiles {
+		record := []string{
+			strconv.Itoa(idx),
+			strconv.FormatInt(int64(tile.Direction), 10),
+			strconv.FormatInt(int64(tile.RoofHeight), 10),
+			strconv.FormatUint(uint64(tile.MaterialFlags.Encode()), 10),
+			strconv.FormatInt(int64(tile.Height), 10),
+			strconv.FormatInt(int64(tile.Width), 10),
+			strconv.FormatInt(int64(tile.Type), 10),
+			strconv.FormatInt(int64(tile.Style), 10),
+			strconv.FormatInt(int64(tile.Sequence), 10),
+			strconv.FormatInt(int64(tile.RarityFrameIndex), 10),
+			strconv.Itoa(len(tile.Blocks)),
+		}
+
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// ImportCSV reads tile metadata in the format written by ExportCSV and applies
+// it to the tiles. The number of rows must match the number of tiles, and the
+// block data is not affected. Nothing is applied if any row is malformed.
+func (d *DT1) ImportCSV(r io.Reader) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = len(csvHeader)
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return err
+	}
+
+	if len(records) == 0 {
+		return fmt.Errorf("missing header row")
+	}
+
+	records = records[1:]
+
+	if len(records) != len(d.Tiles) {
+		const fmtErr = "expected %d rows, got %d instead"
+		return fmt.Errorf(fmtErr, len(d.Tiles), len(records))
+	}
+
+	parsed := make([]Tile, len(records))
+
+	for idx, record := range records {
+		if err := parsed[idx].parseCSVRecord(idx, record, len(d.Tiles[idx].Blocks)); err != nil {
+			return fmt.Errorf("row %d: %v", idx+1, err)
+		}
+	}
+
+	for idx, tile := range d.Tiles {
+		tile.Direction = parsed[idx].Direction
+		tile.RoofHeight = parsed[idx].RoofHeight
+		tile.MaterialFlags = parsed[idx].MaterialFlags
+		tile.Height = parsed[idx].Height
+		tile.Width = parsed[idx].Width
+		tile.Type = parsed[idx].Type
+		tile.Style = parsed[idx].Style
+		tile.Sequence = parsed[idx].Sequence
+		tile.RarityFrameIndex = parsed[idx].RarityFrameIndex
+	}
+
+	d.InvalidateCache()
+
+	return nil
+}
+
+func (t *Tile) parseCSVRecord(index int, record []string, blockCount int) error {
+	values := make([]int64, len(record))
+
+	for idx, field := range record {
+		minValue, maxValue := int64(math.MinInt32), int64(math.MaxInt32)
+
+		switch csvHeader[idx] {
+		case "roofHeight":
+			minValue, maxValue = math.MinInt16, math.MaxInt16
+		case "materialFlags":
+			minValue, maxValue = 0, math.MaxUint16
+		}
+
+		value, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			return fmt.Errorf("column %s: %v", csvHeader[idx], err)
+		}
+
+		if value < minValue || value > maxValue {
+			return fmt.Errorf("column %s: value %d is out of range", csvHeader[idx], value)
+		}
+
+		values[idx] = value
+	}
+
+	if values[0] != int64(index) {
+		return fmt.Errorf("expected tile index %d, got %d instead", index, values[0])
+	}
+
+	if values[10] != int64(blockCount) {
+		return fmt.Errorf("expected block count %d, got %d instead", blockCount, values[10])
+	}
+
+	t.Direction = int32(values[1])
+	t.RoofHeight = int16(values[2])
+	t.MaterialFlags = NewMaterialFlags(uint16(values[3]))
+	t.Height = int32(values[4])
+	t.Width = int32(values[5])
+	t.Type = int32(values[6])
+	t.Style = int32(values[7])
+	t.Sequence = int32(values[8])
+	t.RarityFrameIndex = int32(values[9])
+
+	return nil
+}
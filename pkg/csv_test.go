@@ -0,0 +1,28 @@
+package pkg
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+func TestCSVRoundTrip(t *testing.T) {
+	assert := testify.New(t)
+
+	d := testEncodableDT1()
+
+	buf := &bytes.Buffer{}
+	assert.NoError(d.ExportCSV(buf))
+
+	edited := strings.Replace(buf.String(), "0,3,2,65,-80,160,1,2,3,4,2", "0,3,2,65,-80,160,7,2,3,4,2", 1)
+
+	assert.NoError(d.ImportCSV(strings.NewReader(edited)))
+	assert.Equal(int32(7), d.Tiles[0].Type)
+	assert.Equal(NewMaterialFlags(0x0041), d.Tiles[0].MaterialFlags)
+
+	malformed := strings.Replace(edited, ",7,", ",seven,", 1)
+	assert.ErrorContains(d.ImportCSV(strings.NewReader(malformed)), "column type")
+	assert.Equal(int32(7), d.Tiles[0].Type)
+}
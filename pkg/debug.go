@@ -0,0 +1,131 @@
+package pkg
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DebugDump writes a full, human-readable dump of the DT1 to w: the header
+// fields, followed by the DebugString of every tile and each of its blocks.
+func (d *DT1) DebugDump(w io.Writer) error {
+	major, minor := d.Version()
+
+	if _, err := fmt.Fprintf(w, "Version: %d.%d\nTiles:   %d\n", major, minor, len(d.Tiles)); err != nil {
+		return err
+	}
+
+	for tileIdx, tile := range d.Tiles {
+		if _, err := fmt.Fprintf(w, "\nTile %d\n%s", tileIdx, tile.DebugString()); err != nil {
+			return err
+		}
+
+		for blockIdx, block := range tile.Blocks {
+			dump := fmt.Sprintf("Block %d\n%s", blockIdx, block.DebugString())
+
+			if _, err := io.WriteString(w, indent(dump, "    ")); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// indent prefixes every line of s with prefix
+func indent(s, prefix string) string {
+	lines := strings.SplitAfter(s, "\n")
+
+	for idx, line := range lines {
+		if line != "" {
+			lines[idx] = prefix + line
+		}
+	}
+
+	return strings.Join(lines, "")
+}
+
+// DebugString returns a multi-line, human-readable summary of the tile: its
+// fields, the number of blocks and encoded bytes, and a 5x5 grid of the
+// sub-tile walkability, where '.' is walkable and '#' is blocked.
+func (t *Tile) DebugString() string {
+	sb := &strings.Builder{}
+
+	encodedBytes := 0
+	for _, block := range t.Blocks {
+		encodedBytes += len(block.EncodedData)
+	}
+
+	fmt.Fprintf(sb, "Direction:        %d\n", t.Direction)
+	fmt.Fprintf(sb, "RoofHeight:       %d\n", t.RoofHeight)
+	fmt.Fprintf(sb, "MaterialFlags:    0x%04x\n", t.MaterialFlags.Encode())
+	fmt.Fprintf(sb, "Height:           %d\n", t.Height)
+	fmt.Fprintf(sb, "Width:            %d\n", t.Width)
+	fmt.Fprintf(sb, "Type:             %d\n", t.Type)
+	fmt.Fprintf(sb, "Style:            %d\n", t.Style)
+	fmt.Fprintf(sb, "Sequence:         %d\n", t.Sequence)
+	fmt.Fprintf(sb, "RarityFrameIndex: %d\n", t.RarityFrameIndex)
+	fmt.Fprintf(sb, "Blocks:           %d\n", len(t.Blocks))
+	fmt.Fprintf(sb, "EncodedBytes:     %d\n", encodedBytes)
+	fmt.Fprintf(sb, "Walkability:\n")
+
+	for row := 0; row < gridDivisionsXY; row++ {
+		sb.WriteString("  ")
+
+		for col := 0; col < gridDivisionsXY; col++ {
+			cell := byte('.')
+			if t.SubTileFlags[row*gridDivisionsXY+col].BlockWalk {
+				cell = '#'
+			}
+
+			sb.WriteByte(cell)
+		}
+
+		sb.WriteByte('\n')
+	}
+
+	return sb.String()
+}
+
+// DebugString returns a multi-line, human-readable summary of the block: its
+// position, grid position, format, length and file offset, followed by an
+// ASCII rendering of every pixel row which has non-transparent pixels, where
+// '#' is a non-transparent pixel and '.' is a transparent one.
+func (block *Block) DebugString() string {
+	sb := &strings.Builder{}
+
+	fmt.Fprintf(sb, "Position:     (%d, %d)\n", block.X, block.Y)
+	fmt.Fprintf(sb, "GridPosition: (%d, %d)\n", block.GridX, block.GridY)
+	fmt.Fprintf(sb, "Format:       %s\n", block.format)
+	fmt.Fprintf(sb, "Length:       %d\n", block.Length)
+	fmt.Fprintf(sb, "FileOffset:   %d\n", block.FileOffset)
+
+	if err := block.ensureDecoded(); err != nil {
+		fmt.Fprintf(sb, "Pixels:       %v\n", err)
+		return sb.String()
+	}
+
+	fmt.Fprintf(sb, "Pixels:\n")
+
+	w, h := block.Size()
+
+	for y := 0; y < h; y++ {
+		row := make([]byte, w)
+		opaque := false
+
+		for x := range row {
+			row[x] = '.'
+
+			if block.pixelAt(int32(x), int32(y)) != 0 {
+				row[x] = '#'
+				opaque = true
+			}
+		}
+
+		if opaque {
+			fmt.Fprintf(sb, "  %2d %s\n", y, row)
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,76 @@
+package pkg
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+func TestTileDebugString(t *testing.T) {
+	assert := testify.New(t)
+
+	tile := testEncodableDT1().Tiles[0]
+	tile.SubTileFlags[4] = NewSubTileFlags(0x01)
+
+	dump := tile.DebugString()
+	assert.Contains(dump, "Direction:        3\n")
+	assert.Contains(dump, "MaterialFlags:    0x0041\n")
+	assert.Contains(dump, "Height:           -80\n")
+	assert.Contains(dump, "Blocks:           2\n")
+	assert.Contains(dump, "EncodedBytes:     262\n")
+	assert.Contains(dump, "Walkability:\n  ...##\n  .....\n")
+}
+
+func TestBlockDebugString(t *testing.T) {
+	assert := testify.New(t)
+
+	block := testEncodableDT1().Tiles[0].Blocks[0]
+	block.EncodedData = []byte{1, 2, 7, 8, 0, 0, 0, 0, 0, 1, 9, 0, 0}
+	block.Length = int32(len(block.EncodedData))
+
+	dump := block.DebugString()
+	assert.Contains(dump, "Position:     (16, -32)\n")
+	assert.Contains(dump, "GridPosition: (2, 1)\n")
+	assert.Contains(dump, "Format:       RLE\n")
+	assert.Contains(dump, "Length:       13\n")
+	assert.Contains(dump, "Pixels:\n   0 .##\n   2 #..\n", "transparent rows are left out")
+
+	block.X = 200
+	block.stale = true
+	assert.Contains(block.DebugString(), "Pixels:       block 0: pixel offset")
+}
+
+// failingWriter accepts limit bytes, then fails every write
+type failingWriter struct {
+	limit int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.limit {
+		return 0, errors.New("write failed")
+	}
+
+	w.limit -= len(p)
+
+	return len(p), nil
+}
+
+func TestDebugDump(t *testing.T) {
+	assert := testify.New(t)
+
+	d := testEncodableDT1()
+	sb := &strings.Builder{}
+
+	assert.NoError(d.DebugDump(sb))
+
+	dump := sb.String()
+	assert.True(strings.HasPrefix(dump, "Version: 7.6\nTiles:   1\n\nTile 0\nDirection:        3\n"))
+	assert.Contains(dump, "    Block 0\n    Position:     (16, -32)\n")
+	assert.Contains(dump, "    Block 1\n    Position:     (0, 0)\n")
+
+	for _, limit := range []int{0, 30, len(dump) - 1} {
+		assert.Error(d.DebugDump(&failingWriter{limit: limit}), "limit %d", limit)
+	}
+}
@@ -0,0 +1,147 @@
+package pkg
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+)
+
+// CountUniquePatterns returns the number of distinct tile pixel patterns. Two
+// tiles share a pattern when their blocks have identical pixel data, block by
+// block, regardless of the tile metadata. Blocks which have not been decoded
+// yet are decoded first, and an error is returned if a block can not be
+// decoded.
+func (d *DT1) CountUniquePatterns() (int, error) {
+	patterns := make(map[[sha256.Size]byte]struct{})
+
+	for tileIdx, tile := range d.Tiles {
+		sum, err := tile.pixelHash()
+		if err != nil {
+			const fmtErr = "tile %d: %w"
+			return 0, fmt.Errorf(fmtErr, tileIdx, err)
+		}
+
+		patterns[sum] = struct{}{}
+	}
+
+	return len(patterns), nil
+}
+
+// pixelHash hashes the pixel data of all blocks of the tile
+func (t *Tile) pixelHash() ([sha256.Size]byte, error) {
+	var sum [sha256.Size]byte
+
+	hash := sha256.New()
+	lengthPrefix := make([]byte, 8)
+
+	for _, block := range t.Blocks {
+		if err := block.ensureDecoded(); err != nil {
+			return sum, err
+		}
+
+		// the length prefix keeps the block boundaries part of the pattern
+		binary.LittleEndian.PutUint64(lengthPrefix, uint64(len(block.PixelData)))
+		hash.Write(lengthPrefix)
+		hash.Write(block.PixelData)
+	}
+
+	copy(sum[:], hash.Sum(nil))
+
+	return sum, nil
+}
+
+// RemoveEmptyBlocks removes all blocks which are entirely transparent from
+// all tiles, and returns the number of removed blocks. If any block was
+// removed, the block offsets are normalized afterwards, so every remaining
+// block must have encoded data; otherwise an error is returned and nothing is
+// removed.
+func (d *DT1) RemoveEmptyBlocks() (int, error) {
+	kept := make([][]*Block, len(d.Tiles))
+	removed := 0
+
+	var errMissing error
+
+	for tileIdx, tile := range d.Tiles {
+		kept[tileIdx] = make([]*Block, 0, len(tile.Blocks))
+
+		for blockIdx, block := range tile.Blocks {
+			if block.IsEmpty() {
+				removed++
+				continue
+			}
+
+			if block.EncodedData == nil && errMissing == nil {
+				const fmtErr = "tile %d, block %d: missing encoded data"
+				errMissing = fmt.Errorf(fmtErr, tileIdx, blockIdx)
+			}
+
+			kept[tileIdx] = append(kept[tileIdx], block)
+		}
+	}
+
+	if removed == 0 {
+		return 0, nil
+	}
+
+	if errMissing != nil {
+		return 0, errMissing
+	}
+
+	for tileIdx, tile := range d.Tiles {
+		if len(kept[tileIdx]) == len(tile.Blocks) {
+			continue
+		}
+
+		tile.Blocks = kept[tileIdx]
+
+		// the vertical offset of the tile may have changed
+		for _, block := range tile.Blocks {
+			block.stale = true
+		}
+	}
+
+	d.InvalidateCache()
+
+	return removed, d.NormalizeBlockOffsets()
+}
+
+// DeduplicateTiles lays out the block data of every tile which Equals an
+// earlier tile only once, and returns the number of tiles which share the
+// block data of an earlier tile. The tiles stay distinct values, only the tile
+// headers of the duplicates point at the blocks of the earlier tile. Every
+// block must have encoded data, otherwise an error is returned and nothing is
+// changed.
+//
+// The sharing lasts until the next NormalizeBlockOffsets, which lays out every
+// tile separately again. Until then, ToBytes returns an error if the blocks of
+// tiles sharing block data were edited to differ.
+func (d *DT1) DeduplicateTiles() (int, error) {
+	if err := d.checkEncodedData(); err != nil {
+		return 0, err
+	}
+
+	shared := make(map[*Tile]*Tile)
+
+	for idx, tile := range d.Tiles {
+		for _, canonical := range d.Tiles[:idx] {
+			if _, found := shared[canonical]; found {
+				continue
+			}
+
+			if canonical != tile && canonical.Equals(tile) {
+				shared[tile] = canonical
+				break
+			}
+		}
+	}
+
+	if len(shared) == 0 {
+		return 0, nil
+	}
+
+	if err := d.layoutBlocks(shared); err != nil {
+		return 0, err
+	}
+
+	return len(shared), nil
+}
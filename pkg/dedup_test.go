@@ -0,0 +1,150 @@
+package pkg
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+func TestDeduplicateTiles(t *testing.T) {
+	assert := testify.New(t)
+
+	d := testEncodableDT1()
+	d.Tiles = append(d.Tiles, d.Tiles[0].clone(d))
+	assert.NoError(d.NormalizeBlockOffsets())
+
+	before, err := d.ToBytes()
+	assert.NoError(err)
+
+	shared, err := d.DeduplicateTiles()
+	assert.NoError(err)
+	assert.Equal(1, shared)
+	assert.NotSame(d.Tiles[0], d.Tiles[1])
+	assert.Equal(d.Tiles[0].blockHeaderPointer, d.Tiles[1].blockHeaderPointer)
+
+	after, err := d.ToBytes()
+	assert.NoError(err)
+	assert.Less(len(after), len(before))
+
+	decoded, err := FromBytes(after)
+	assert.NoError(err)
+	assert.Len(decoded.Tiles, 2)
+	assert.True(decoded.Tiles[1].Equals(d.Tiles[0]))
+}
+
+func TestDeduplicateTilesKeepsTilesDistinct(t *testing.T) {
+	assert := testify.New(t)
+
+	d := testEncodableDT1()
+	d.Tiles = append(d.Tiles, d.Tiles[0].clone(d))
+
+	_, err := d.DeduplicateTiles()
+	assert.NoError(err)
+
+	d.Tiles[1].Style = 9
+	assert.Equal(int32(2), d.Tiles[0].Style)
+
+	data, err := d.ToBytes()
+	assert.NoError(err)
+
+	decoded, err := FromBytes(data)
+	assert.NoError(err)
+	assert.Equal(int32(2), decoded.Tiles[0].Style)
+	assert.Equal(int32(9), decoded.Tiles[1].Style)
+
+	// the blocks still share their data, so they may not differ
+	d.Tiles[1].Blocks[0].EncodedData = []byte{1, 2, 9, 9, 0, 0}
+
+	_, err = d.ToBytes()
+	assert.Error(err)
+
+	assert.NoError(d.NormalizeBlockOffsets())
+
+	data, err = d.ToBytes()
+	assert.NoError(err)
+
+	decoded, err = FromBytes(data)
+	assert.NoError(err)
+	assert.Equal([]byte{1, 2, 7, 8, 0, 0}, decoded.Tiles[0].Blocks[0].EncodedData)
+	assert.Equal([]byte{1, 2, 9, 9, 0, 0}, decoded.Tiles[1].Blocks[0].EncodedData)
+}
+
+func TestDeduplicateTilesMissingEncodedData(t *testing.T) {
+	assert := testify.New(t)
+
+	d := testEncodableDT1()
+	d.Tiles = append(d.Tiles, d.Tiles[0].clone(d), d.Tiles[0].clone(d))
+	d.Tiles[2].Blocks[1].EncodedData = nil
+
+	pointer := d.Tiles[1].blockHeaderPointer
+
+	shared, err := d.DeduplicateTiles()
+	assert.Error(err)
+	assert.Zero(shared)
+	assert.Equal(pointer, d.Tiles[1].blockHeaderPointer)
+}
+
+func TestRemoveEmptyBlocks(t *testing.T) {
+	assert := testify.New(t)
+
+	d := NewTestDT1(2, 3)
+	d.Tiles[0].Blocks[1].EncodedData = make([]byte, blockDataLength)
+
+	removed, err := d.RemoveEmptyBlocks()
+	assert.NoError(err)
+	assert.Equal(1, removed)
+	assert.Len(d.Tiles[0].Blocks, 2)
+	assert.Len(d.Tiles[1].Blocks, 3)
+
+	data, err := d.ToBytes()
+	assert.NoError(err)
+
+	decoded, err := FromBytes(data)
+	assert.NoError(err)
+	assert.True(decoded.Tiles[0].Equals(d.Tiles[0]))
+
+	removed, err = d.RemoveEmptyBlocks()
+	assert.NoError(err)
+	assert.Zero(removed)
+}
+
+func TestRemoveEmptyBlocksMissingEncodedData(t *testing.T) {
+	assert := testify.New(t)
+
+	d := NewTestDT1(1, 3)
+	d.Tiles[0].Blocks[1].EncodedData = make([]byte, blockDataLength)
+	d.Tiles[0].Blocks[2].PixelData = []byte{1}
+	d.Tiles[0].Blocks[2].EncodedData = nil
+
+	removed, err := d.RemoveEmptyBlocks()
+	assert.Error(err)
+	assert.Zero(removed)
+	assert.Len(d.Tiles[0].Blocks, 3)
+}
+
+func TestCountUniquePatterns(t *testing.T) {
+	assert := testify.New(t)
+
+	d := NewTestDT1(3, 4)
+
+	patterns, err := d.CountUniquePatterns()
+	assert.NoError(err)
+	assert.Equal(3, patterns)
+
+	// the metadata of a tile is not part of its pattern
+	clone := d.Tiles[0].clone(d)
+	clone.Style = 9
+	d.Tiles = append(d.Tiles, clone)
+
+	patterns, err = d.CountUniquePatterns()
+	assert.NoError(err)
+	assert.Equal(3, patterns)
+
+	corrupt := d.Tiles[1].clone(d)
+	corrupt.Blocks[0].X = 160
+	corrupt.Blocks[0].PixelData = nil
+	d.Tiles = append(d.Tiles, corrupt)
+
+	_, err = d.CountUniquePatterns()
+	assert.ErrorContains(err, "tile 4")
+}
@@ -0,0 +1,154 @@
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+)
+
+// DT1Diff is the structural difference between two DT1s. Tiles are matched up
+// by their index.
+type DT1Diff struct {
+	// Removed holds the indices of the tiles which only exist in the receiver
+	Removed []int
+
+	// Added holds the tiles which only exist in the other DT1
+	Added []TileDiff
+
+	// Changed holds the tiles of the other DT1 which exist in both, but differ
+	Changed []TileDiff
+}
+
+// TileDiff is a tile of a DT1Diff, along with its index
+type TileDiff struct {
+	Index int
+	Tile  *Tile
+}
+
+// IsEmpty returns true if the diff does not contain any differences
+func (diff DT1Diff) IsEmpty() bool {
+	return len(diff.Removed) == 0 && len(diff.Added) == 0 && len(diff.Changed) == 0
+}
+
+// Diff compares the receiver to other, tile by tile, using Tile.Equals.
+func (d *DT1) Diff(other *DT1) DT1Diff {
+	diff := DT1Diff{
+		Removed: make([]int, 0),
+		Added:   make([]TileDiff, 0),
+		Changed: make([]TileDiff, 0),
+	}
+
+	for idx, tile := range d.Tiles {
+		if idx >= len(other.Tiles) {
+			diff.Removed = append(diff.Removed, idx)
+			continue
+		}
+
+		if !tile.Equals(other.Tiles[idx]) {
+			diff.Changed = append(diff.Changed, TileDiff{Index: idx, Tile: other.Tiles[idx]})
+		}
+	}
+
+	for idx := len(d.Tiles); idx < len(other.Tiles); idx++ {
+		diff.Added = append(diff.Added, TileDiff{Index: idx, Tile: other.Tiles[idx]})
+	}
+
+	return diff
+}
+
+// ApplyDiff applies a diff produced by Diff to the receiver: changed tiles are
+// replaced, removed tiles are removed and added tiles are appended. The tiles
+// taken from the diff are cloned. Nothing is applied if the diff does not fit
+// the receiver. Call NormalizeBlockOffsets before writing the result with
+// ToBytes.
+func (d *DT1) ApplyDiff(diff DT1Diff) error {
+	removed := make(map[int]bool)
+
+	for _, idx := range diff.Removed {
+		if idx < 0 || idx >= len(d.Tiles) {
+			return fmt.Errorf("removed tile %d does not exist", idx)
+		}
+
+		removed[idx] = true
+	}
+
+	for _, change := range diff.Changed {
+		if change.Index < 0 || change.Index >= len(d.Tiles) || removed[change.Index] {
+			return fmt.Errorf("changed tile %d does not exist", change.Index)
+		}
+	}
+
+	numTiles := len(d.Tiles) - len(removed)
+
+	for idx, addition := range diff.Added {
+		if addition.Index != numTiles+idx {
+			const fmtErr = "added tile %d does not follow the last tile %d"
+			return fmt.Errorf(fmtErr, addition.Index, numTiles+idx-1)
+		}
+	}
+
+	for _, change := range diff.Changed {
+		d.Tiles[change.Index] = change.Tile.clone(d)
+	}
+
+	removedIndices := append([]int{}, diff.Removed...)
+	sort.Sort(sort.Reverse(sort.IntSlice(removedIndices)))
+
+	for _, idx := range removedIndices {
+		d.Tiles = append(d.Tiles[:idx], d.Tiles[idx+1:]...)
+	}
+
+	for _, addition := range diff.Added {
+		d.Tiles = append(d.Tiles, addition.Tile.clone(d))
+	}
+
+	d.InvalidateCache()
+
+	return nil
+}
+
+// Equals returns true if both tiles have the same metadata and blocks with
+// the same metadata and encoded data. The layout of the blocks within the
+// file (the file offsets) is not compared.
+func (t *Tile) Equals(other *Tile) bool {
+	if t == other {
+		return true
+	}
+
+	if t == nil || other == nil {
+		return false
+	}
+
+	sameMetadata := t.Direction == other.Direction &&
+		t.RoofHeight == other.RoofHeight &&
+		t.MaterialFlags == other.MaterialFlags &&
+		t.Height == other.Height &&
+		t.Width == other.Width &&
+		t.Type == other.Type &&
+		t.Style == other.Style &&
+		t.Sequence == other.Sequence &&
+		t.RarityFrameIndex == other.RarityFrameIndex &&
+		t.SubTileFlags == other.SubTileFlags
+
+	if !sameMetadata || len(t.Blocks) != len(other.Blocks) {
+		return false
+	}
+
+	for idx, block := range t.Blocks {
+		if !block.equals(other.Blocks[idx]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (block *Block) equals(other *Block) bool {
+	return block.X == other.X &&
+		block.Y == other.Y &&
+		block.GridX == other.GridX &&
+		block.GridY == other.GridY &&
+		block.format == other.format &&
+		block.Length == other.Length &&
+		bytes.Equal(block.EncodedData, other.EncodedData)
+}
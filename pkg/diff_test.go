@@ -0,0 +1,42 @@
+package pkg
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	assert := testify.New(t)
+
+	a, b := testEncodableDT1(), testEncodableDT1()
+	assert.True(a.Diff(b).IsEmpty())
+
+	b.Tiles[0].Style = 9
+	b.Tiles = append(b.Tiles, testEncodableDT1().Tiles[0])
+
+	diff := a.Diff(b)
+	assert.Empty(diff.Removed)
+	assert.Len(diff.Changed, 1)
+	assert.Equal(0, diff.Changed[0].Index)
+	assert.Len(diff.Added, 1)
+	assert.Equal(1, diff.Added[0].Index)
+
+	reverse := b.Diff(a)
+	assert.Equal([]int{1}, reverse.Removed)
+}
+
+func TestApplyDiff(t *testing.T) {
+	assert := testify.New(t)
+
+	a, b := testEncodableDT1(), testEncodableDT1()
+	b.Tiles[0].Style = 9
+	b.Tiles = append(b.Tiles, testEncodableDT1().Tiles[0])
+
+	assert.NoError(a.ApplyDiff(a.Diff(b)))
+	assert.True(a.Diff(b).IsEmpty())
+	assert.Same(a, a.Tiles[1].dt1)
+
+	assert.Error(a.ApplyDiff(DT1Diff{Removed: []int{5}}))
+	assert.Len(a.Tiles, 2)
+}
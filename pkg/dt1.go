@@ -10,7 +10,7 @@ import (
 
 // FromBytes loads a DT1 record
 func FromBytes(fileData []byte) (result *DT1, err error) {
-	result = &DT1{}
+	result = &DT1{fileSize: len(fileData)}
 	stream := bitstream.ReaderFromBytes(fileData...)
 
 	if err = result.decodeDT1Header(stream); err != nil {
@@ -26,10 +26,21 @@ func FromBytes(fileData []byte) (result *DT1, err error) {
 
 // DT1 represents a DT1 file.
 type DT1 struct {
-	Tiles   []*Tile
-	palette color.Palette
+	Tiles    []*Tile
+	palette  color.Palette
+	cache    tileCache
+	fileSize int // size of the file the DT1 was read from, if any
+	version  struct {
+		major, minor int32
+	}
 }
 
+const (
+	// versionMajor and versionMinor are the only supported DT1 version, 7.6
+	versionMajor = 7
+	versionMinor = 6
+)
+
 // BlockDataFormat represents the format of the block data
 type BlockDataFormat int16
 
@@ -41,6 +52,18 @@ const (
 	BlockFormatIsometric BlockDataFormat = 1
 )
 
+// String returns the name of the block format
+func (f BlockDataFormat) String() string {
+	switch f {
+	case BlockFormatRLE:
+		return "RLE"
+	case BlockFormatIsometric:
+		return "Isometric"
+	}
+
+	return fmt.Sprintf("BlockDataFormat(%d)", int16(f))
+}
+
 func (d *DT1) decodeDT1Header(stream *bitstream.Reader) error {
 	const (
 		unknownDataBytes = 260
@@ -67,6 +90,13 @@ func (d *DT1) decodeDT1Header(stream *bitstream.Reader) error {
 		return err
 	}
 
+	// the counts are checked against the file size before allocating anything
+	tileDataEnd := int64(tileDataStartAddress) + int64(numberOfTiles)*tileHeaderLength
+	if tileDataStartAddress < 0 || numberOfTiles < 0 || tileDataEnd > int64(d.fileSize) {
+		const fmtErr = "%d tile headers at offset %d do not fit in a file of %d bytes"
+		return fmt.Errorf(fmtErr, numberOfTiles, tileDataStartAddress, d.fileSize)
+	}
+
 	stream.SetPosition(int(tileDataStartAddress))
 
 	d.Tiles = make([]*Tile, numberOfTiles)
@@ -89,7 +119,7 @@ func (d *DT1) decodeDT1Body(stream *bitstream.Reader) error {
 func (d *DT1) decodeDT1Version(stream *bitstream.Reader) error {
 	const (
 		v1Bytes, v2Bytes       = 4, 4
-		expectedV1, expectedV2 = 7, 6
+		expectedV1, expectedV2 = versionMajor, versionMinor
 	)
 
 	ver1, _ := stream.Next(v1Bytes).Bytes().AsInt32()
@@ -99,9 +129,21 @@ func (d *DT1) decodeDT1Version(stream *bitstream.Reader) error {
 		return fmt.Errorf(fmtErr, expectedV1, expectedV2, ver1, ver2)
 	}
 
+	d.version.major, d.version.minor = ver1, ver2
+
 	return nil
 }
 
+// Version returns the version numbers read from the header. A DT1 which was
+// not read from a file reports the supported version, 7.6.
+func (d *DT1) Version() (v1, v2 int32) {
+	if d.version.major == 0 && d.version.minor == 0 {
+		return versionMajor, versionMinor
+	}
+
+	return d.version.major, d.version.minor
+}
+
 func (d *DT1) decodeTilesStage1(stream *bitstream.Reader) error {
 	const (
 		directionBytes  = 4
@@ -159,6 +201,13 @@ func (d *DT1) decodeTilesStage1(stream *bitstream.Reader) error {
 		newTile.blockHeaderPointer, _ = stream.Next(tileBlockHeaderPointerBytes).Bytes().AsInt32()
 		newTile.blockHeaderSize, _ = stream.Next(tileBlockHeaderSizeBytes).Bytes().AsInt32()
 		numBlocks, _ := stream.Next(tileNumBlocksBytes).Bytes().AsInt32()
+
+		blockHeadersEnd := int64(newTile.blockHeaderPointer) + int64(numBlocks)*blockHeaderLength
+		if numBlocks < 0 || newTile.blockHeaderPointer < 0 || blockHeadersEnd > int64(d.fileSize) {
+			const fmtErr = "tile %d: %d block headers at offset %d do not fit in a file of %d bytes"
+			return fmt.Errorf(fmtErr, tileIdx, numBlocks, newTile.blockHeaderPointer, d.fileSize)
+		}
+
 		newTile.Blocks = make([]*Block, numBlocks)
 
 		err := stream.Next(unknownData4Bytes).Bytes().Error // skip, check error
@@ -224,7 +273,13 @@ func (t *Tile) decodeBlockHeaders(stream *bitstream.Reader) (err error) {
 
 func (t *Tile) decodeBlockBodies(stream *bitstream.Reader) error {
 	for blockIndex, block := range t.Blocks {
-		stream.SetPosition(int(t.blockHeaderPointer + block.FileOffset))
+		dataStart := int64(t.blockHeaderPointer) + int64(block.FileOffset)
+		if block.Length < 0 || dataStart < 0 || dataStart+int64(block.Length) > int64(t.dt1.fileSize) {
+			const fmtErr = "block %d: %d bytes of data at offset %d do not fit in a file of %d bytes"
+			return fmt.Errorf(fmtErr, blockIndex, block.Length, dataStart, t.dt1.fileSize)
+		}
+
+		stream.SetPosition(int(dataStart))
 		encodedData, err := stream.Next(int(block.Length)).Bytes().AsBytes()
 		if err != nil {
 			return err
@@ -278,3 +333,119 @@ func defaultPalette() color.Palette {
 
 	return palette
 }
+
+// UnusedPaletteIndices returns the palette indices which are not referenced by
+// the pixel data of any tile. Blocks which have not been decoded yet are
+// decoded first, and an error is returned if a block can not be decoded. Index
+// 0 is reserved for transparency, so it is never reported as unused.
+func (d *DT1) UnusedPaletteIndices() ([]uint8, error) {
+	const numColors = 256
+
+	var used [numColors]bool
+
+	used[0] = true
+
+	for tileIdx, tile := range d.Tiles {
+		for _, block := range tile.Blocks {
+			if err := block.ensureDecoded(); err != nil {
+				const fmtErr = "tile %d: %w"
+				return nil, fmt.Errorf(fmtErr, tileIdx, err)
+			}
+
+			for _, palIdx := range block.PixelData {
+				used[palIdx] = true
+			}
+		}
+	}
+
+	unused := make([]uint8, 0)
+
+	for idx := range used {
+		if !used[idx] {
+			unused = append(unused, uint8(idx))
+		}
+	}
+
+	return unused, nil
+}
+
+// TotalPixelMemory returns the number of bytes which decoding all blocks
+// would allocate for PixelData. Every block's pixel buffer has the dimensions
+// of its tile, so this is computed from the tile dimensions alone and does not
+// require the blocks to be decoded.
+func (d *DT1) TotalPixelMemory() int64 {
+	var total int64
+
+	for _, tile := range d.Tiles {
+		tileArea := int64(tile.Width) * int64(tile.WallHeight())
+		total += tileArea * int64(len(tile.Blocks))
+	}
+
+	return total
+}
+
+// TotalTileArea returns the sum of the pixel areas of all tiles, using the
+// absolute tile height. This is the size of one pixel buffer per tile, which
+// is useful for planning memory before calling DecodeAllGraphics.
+func (d *DT1) TotalTileArea() int64 {
+	var total int64
+
+	for _, tile := range d.Tiles {
+		total += int64(tile.Width) * int64(AbsInt32(tile.Height))
+	}
+
+	return total
+}
+
+// SetAllPaletteIndices sets every non-transparent pixel of every decoded
+// block to the given palette index, which yields a solid silhouette of each
+// tile. The encoded data is updated to match. Blocks which have not been
+// decoded are left untouched.
+func (d *DT1) SetAllPaletteIndices(index uint8) {
+	var remapTable [256]uint8
+
+	for idx := 1; idx < len(remapTable); idx++ {
+		remapTable[idx] = index
+	}
+
+	d.ForEachBlock(func(_, _ int, block *Block) bool {
+		if block.PixelData == nil || block.stale {
+			return true
+		}
+
+		for idx, palIdx := range block.PixelData {
+			block.PixelData[idx] = remapTable[palIdx]
+		}
+
+		block.remapEncodedData(remapTable)
+
+		return true
+	})
+}
+
+// TotalEncodedBytes returns the sum of the Length of all blocks, which is the
+// size of all block data as it would be written by ToBytes.
+func (d *DT1) TotalEncodedBytes() int64 {
+	var total int64
+
+	d.ForEachBlock(func(_, _ int, block *Block) bool {
+		total += int64(block.Length)
+		return true
+	})
+
+	return total
+}
+
+// ReparentBlocks sets the back-pointers of all tiles to the DT1, and of all
+// blocks to the tile which holds them. This is needed after tiles were moved
+// into the Tiles slice from another DT1, or built by hand, as the methods
+// which decode and render blocks rely on these pointers.
+func (d *DT1) ReparentBlocks() {
+	for _, tile := range d.Tiles {
+		tile.dt1 = d
+
+		for _, block := range tile.Blocks {
+			block.tile = tile
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package pkg
+
+import (
+	"bytes"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+func TestUnusedPaletteIndices(t *testing.T) {
+	assert := testify.New(t)
+
+	d := NewTestDT1(1, 1)
+
+	unused, err := d.UnusedPaletteIndices()
+	assert.NoError(err)
+	assert.Len(unused, 253)
+	assert.Equal(uint8(3), unused[0])
+	assert.Equal(uint8(255), unused[len(unused)-1])
+	assert.NotContains(unused, uint8(0), "transparency is never unused")
+
+	d.Tiles[0].Blocks[0].X = 160
+	d.Tiles[0].Blocks[0].stale = true
+
+	_, err = d.UnusedPaletteIndices()
+	assert.Error(err)
+}
+
+func TestTotalPixelMemory(t *testing.T) {
+	assert := testify.New(t)
+
+	d := NewTestDT1(2, 3)
+	wall := testEncodableDT1().Tiles[0]
+	wall.dt1 = d
+	d.Tiles = append(d.Tiles, wall)
+
+	assert.Equal(int64(2*3*160*80+2*160*80), d.TotalPixelMemory())
+
+	// the estimate matches the buffers which decoding allocates
+	assert.NoError(d.DecodeAllGraphics())
+	assert.Equal(d.Memory().PixelData, d.TotalPixelMemory())
+}
+
+func TestVersion(t *testing.T) {
+	assert := testify.New(t)
+
+	major, minor := NewTestDT1(0, 0).Version()
+	assert.Equal(int32(7), major)
+	assert.Equal(int32(6), minor)
+
+	d, err := FromFile(benchmarkFile)
+	assert.NoError(err)
+
+	major, minor = d.Version()
+	assert.Equal(int32(7), major)
+	assert.Equal(int32(6), minor)
+}
+
+func TestTotalTileArea(t *testing.T) {
+	assert := testify.New(t)
+
+	d := NewTestDT1(2, 3)
+	wall := testEncodableDT1().Tiles[0]
+	wall.Height = -96
+	d.Tiles = append(d.Tiles, wall)
+
+	// one buffer per tile, regardless of the number of blocks
+	assert.Equal(int64(2*160*80+160*96), d.TotalTileArea())
+	assert.Zero(NewTestDT1(0, 0).TotalTileArea())
+}
+
+func TestSetAllPaletteIndices(t *testing.T) {
+	assert := testify.New(t)
+
+	d := testEncodableDT1()
+	rle, isometric := d.Tiles[0].Blocks[0], d.Tiles[0].Blocks[1]
+	assert.NoError(rle.DecodeRLE())
+
+	d.SetAllPaletteIndices(42)
+
+	assert.Equal([]byte{0, 42, 42, 0}, rle.PixelData[16:20])
+	assert.Equal([]byte{1, 2, 42, 42, 0, 0}, rle.EncodedData)
+	assert.Equal(bytes.Repeat([]byte{5}, blockDataLength), isometric.EncodedData, "blocks which are not decoded are left untouched")
+
+	data, err := d.ToBytes()
+	assert.NoError(err)
+
+	decoded, err := FromBytes(data)
+	assert.NoError(err)
+	assert.Equal(rle.EncodedData, decoded.Tiles[0].Blocks[0].EncodedData)
+}
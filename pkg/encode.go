@@ -0,0 +1,531 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+)
+
+const (
+	fileHeaderLength  = 276 // version, unknown data, number of tiles, tile data address
+	tileHeaderLength  = 96
+	blockHeaderLength = 20
+)
+
+// ToBytes encodes the DT1 into its binary representation. The tile headers
+// are written directly after the file header, the block headers and block
+// data are written at the offsets stored in the tiles and blocks. Every block
+// must have encoded data, otherwise an error naming the offending blocks is
+// returned. Distinct tiles may point at the same block data, see
+// DeduplicateTiles, but only while their blocks are identical; otherwise an
+// error is returned, as one tile would overwrite the data of the other.
+func (d *DT1) ToBytes() ([]byte, error) {
+	if err := d.checkEncodedData(); err != nil {
+		return nil, err
+	}
+
+	tileDataEnd := fileHeaderLength + len(d.Tiles)*tileHeaderLength
+	size := tileDataEnd
+	writers := make(map[int32]*Tile, len(d.Tiles))
+
+	for tileIdx, tile := range d.Tiles {
+		if len(tile.Blocks) == 0 {
+			continue
+		}
+
+		if int(tile.blockHeaderPointer) < tileDataEnd {
+			const fmtErr = "tile %d: block header pointer %d overlaps the tile headers"
+			return nil, fmt.Errorf(fmtErr, tileIdx, tile.blockHeaderPointer)
+		}
+
+		if writer, found := writers[tile.blockHeaderPointer]; found && !writer.sameBlocks(tile) {
+			const fmtErr = "tile %d: shares the block data at %d with a tile whose blocks differ"
+			return nil, fmt.Errorf(fmtErr, tileIdx, tile.blockHeaderPointer)
+		}
+
+		writers[tile.blockHeaderPointer] = tile
+
+		size = maxInt(size, int(tile.blockHeaderPointer)+len(tile.Blocks)*blockHeaderLength)
+
+		for blockIdx, block := range tile.Blocks {
+			if block.FileOffset < 0 {
+				const fmtErr = "tile %d, block %d: negative file offset %d"
+				return nil, fmt.Errorf(fmtErr, tileIdx, blockIdx, block.FileOffset)
+			}
+
+			size = maxInt(size, int(tile.blockHeaderPointer+block.FileOffset)+len(block.EncodedData))
+		}
+	}
+
+	data := make([]byte, size)
+
+	copy(data, d.fileHeaderBytes())
+
+	for tileIdx, tile := range d.Tiles {
+		copy(data[fileHeaderLength+tileIdx*tileHeaderLength:], tile.headerBytes())
+
+		if len(tile.Blocks) == 0 {
+			continue
+		}
+
+		copy(data[tile.blockHeaderPointer:], tile.BlockHeaderBytes())
+
+		for _, block := range tile.Blocks {
+			copy(data[tile.blockHeaderPointer+block.FileOffset:], block.EncodedData)
+		}
+	}
+
+	return data, nil
+}
+
+// NormalizeBlockOffsets recomputes the block header pointers of all tiles and
+// the file offsets of all blocks, so that the binary layout is compact and
+// without overlaps: the block headers of each tile, followed by the encoded
+// data of its blocks, directly after the tile headers. The Length of every
+// block is set to the length of its encoded data. This is required before
+// calling ToBytes after blocks were added, removed or resized. A tile which
+// appears more than once in Tiles has its blocks written only once, and all of
+// its tile headers point at them.
+func (d *DT1) NormalizeBlockOffsets() error {
+	return d.layoutBlocks(nil)
+}
+
+// layoutBlocks implements NormalizeBlockOffsets. A tile which is a key of
+// shared gets no block region of its own, it points at the region of the tile
+// it maps to instead. That tile must come earlier in Tiles and have the same
+// blocks.
+func (d *DT1) layoutBlocks(shared map[*Tile]*Tile) error {
+	for tileIdx, tile := range d.Tiles {
+		for blockIdx, block := range tile.Blocks {
+			if block.EncodedData == nil {
+				return fmt.Errorf("tile %d, block %d: missing encoded data", tileIdx, blockIdx)
+			}
+		}
+	}
+
+	cursor := int32(fileHeaderLength)
+
+	for _, tile := range d.Tiles {
+		cursor += int32(tile.SerializedHeaderSize())
+	}
+
+	laidOut := make(map[*Tile]struct{}, len(d.Tiles))
+
+	for _, tile := range d.Tiles {
+		if _, found := laidOut[tile]; found {
+			continue
+		}
+
+		laidOut[tile] = struct{}{}
+
+		if canonical, found := shared[tile]; found {
+			tile.blockHeaderPointer = canonical.blockHeaderPointer
+			tile.blockHeaderSize = canonical.blockHeaderSize
+
+			for blockIdx, block := range tile.Blocks {
+				block.Length = canonical.Blocks[blockIdx].Length
+				block.FileOffset = canonical.Blocks[blockIdx].FileOffset
+			}
+
+			continue
+		}
+
+		tile.blockHeaderPointer = cursor
+
+		var dataOffset int32
+
+		for _, block := range tile.Blocks {
+			dataOffset += int32(block.SerializedHeaderSize())
+		}
+
+		for _, block := range tile.Blocks {
+			block.Length = int32(len(block.EncodedData))
+			block.FileOffset = dataOffset
+			dataOffset += block.Length
+		}
+
+		tile.blockHeaderSize = dataOffset
+		cursor += dataOffset
+	}
+
+	return nil
+}
+
+// RebuildFromTiles constructs a new DT1 from the given tiles, using the
+// palette of the receiver. The tiles and their blocks are cloned, and all
+// offsets are computed from scratch, so the result can be written with
+// ToBytes. Every block must have encoded data.
+func (d *DT1) RebuildFromTiles(tiles []*Tile) (*DT1, error) {
+	rebuilt := &DT1{
+		Tiles:   make([]*Tile, len(tiles)),
+		palette: d.palette,
+	}
+
+	for idx, tile := range tiles {
+		rebuilt.Tiles[idx] = tile.clone(rebuilt)
+	}
+
+	if err := rebuilt.NormalizeBlockOffsets(); err != nil {
+		return nil, err
+	}
+
+	return rebuilt, nil
+}
+
+// checkEncodedData verifies that every block has encoded data that matches its
+// Length, so that it can be written.
+func (d *DT1) checkEncodedData() error {
+	missing := make([]string, 0)
+
+	for tileIdx, tile := range d.Tiles {
+		for blockIdx, block := range tile.Blocks {
+			if block.EncodedData == nil {
+				missing = append(missing, fmt.Sprintf("tile %d block %d", tileIdx, blockIdx))
+				continue
+			}
+
+			if int(block.Length) != len(block.EncodedData) {
+				const fmtErr = "tile %d, block %d: length %d does not match %d bytes of encoded data"
+				return fmt.Errorf(fmtErr, tileIdx, blockIdx, block.Length, len(block.EncodedData))
+			}
+		}
+	}
+
+	if len(missing) > 0 {
+		return errors.New("missing encoded data for " + strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+func (d *DT1) fileHeaderBytes() []byte {
+	const unknownDataBytes = 260
+
+	w := &byteWriter{}
+
+	major, minor := d.Version()
+
+	w.int32(major)
+	w.int32(minor)
+	w.skip(unknownDataBytes)
+	w.int32(int32(len(d.Tiles)))
+	w.int32(fileHeaderLength)
+
+	return w.data
+}
+
+// TileHeaderBytes serializes the header of the tile at the given index, in the
+// 96 byte on-disk layout. An error is returned if the index is out of range.
+func (d *DT1) TileHeaderBytes(index int) ([]byte, error) {
+	tile, err := d.TileByIndex(index)
+	if err != nil {
+		return nil, err
+	}
+
+	return tile.headerBytes(), nil
+}
+
+// SerializedHeaderSize returns the number of bytes the tile's header occupies
+// in the binary format. The block headers are stored separately.
+func (t *Tile) SerializedHeaderSize() int {
+	return tileHeaderLength
+}
+
+func (t *Tile) headerBytes() []byte {
+	const (
+		unknownData1Bytes = 4
+		unknownData2Bytes = 4
+		unknownData3Bytes = 7
+		unknownData4Bytes = 12
+	)
+
+	w := &byteWriter{}
+
+	w.int32(t.Direction)
+	w.int16(t.RoofHeight)
+	w.uint16(t.MaterialFlags.Encode())
+	w.int32(t.Height)
+	w.int32(t.Width)
+	w.skip(unknownData1Bytes)
+	w.int32(t.Type)
+	w.int32(t.Style)
+	w.int32(t.Sequence)
+	w.int32(t.RarityFrameIndex)
+	w.skip(unknownData2Bytes)
+
+	for _, flags := range t.SubTileFlags {
+		w.byte(flags.Encode())
+	}
+
+	w.skip(unknownData3Bytes)
+	w.int32(t.blockHeaderPointer)
+	w.int32(t.blockHeaderSize)
+	w.int32(int32(len(t.Blocks)))
+	w.skip(unknownData4Bytes)
+
+	return w.data
+}
+
+// SerializedHeaderSize returns the number of bytes the block's header occupies
+// in the binary format. The encoded data is stored separately.
+func (block *Block) SerializedHeaderSize() int {
+	return blockHeaderLength
+}
+
+// sameBlocks reports whether both tiles serialize to the same block headers
+// and block data.
+func (t *Tile) sameBlocks(other *Tile) bool {
+	if t == other {
+		return true
+	}
+
+	if !bytes.Equal(t.BlockHeaderBytes(), other.BlockHeaderBytes()) {
+		return false
+	}
+
+	for idx, block := range t.Blocks {
+		if !bytes.Equal(block.EncodedData, other.Blocks[idx].EncodedData) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// BlockHeaderBytes serializes the headers of all blocks of the tile, in the
+// on-disk layout: 20 bytes per block, holding the position, grid cell, format,
+// Length and FileOffset of the block.
+func (t *Tile) BlockHeaderBytes() []byte {
+	const (
+		blockUnknown1Bytes = 2
+		blockUnknown2Bytes = 2
+	)
+
+	w := &byteWriter{}
+
+	for _, block := range t.Blocks {
+		w.int16(block.X)
+		w.int16(block.Y)
+		w.skip(blockUnknown1Bytes)
+		w.byte(block.GridX)
+		w.byte(block.GridY)
+		w.int16(int16(block.format))
+		w.int32(block.Length)
+		w.skip(blockUnknown2Bytes)
+		w.int32(block.FileOffset)
+	}
+
+	return w.data
+}
+
+// EncodeFromRGBA quantizes the image to the nearest colors of the palette and
+// stores the result as the block's PixelData, then encodes it into
+// EncodedData according to the block's format. The image must have the
+// dimensions of the parent tile. Fully transparent pixels become index 0, an
+// error is returned if any other pixel is quantized to index 0.
+func (block *Block) EncodeFromRGBA(img *image.RGBA, palette color.Palette) error {
+	if block.tile == nil {
+		return errors.New("block does not belong to a tile")
+	}
+
+	bounds := img.Bounds()
+	tw, th := int(block.tile.Width), block.tile.WallHeight()
+
+	if bounds.Dx() != tw || bounds.Dy() != th {
+		const fmtErr = "image is %dx%d, expected the tile dimensions %dx%d"
+		return fmt.Errorf(fmtErr, bounds.Dx(), bounds.Dy(), tw, th)
+	}
+
+	pixels := make([]byte, tw*th)
+
+	for y := 0; y < th; y++ {
+		for x := 0; x < tw; x++ {
+			c := img.RGBAAt(bounds.Min.X+x, bounds.Min.Y+y)
+			if c.A == 0 {
+				continue
+			}
+
+			palIdx := palette.Index(c)
+			if palIdx == 0 {
+				const fmtErr = "pixel (%d, %d) is not transparent, but was quantized to palette index 0"
+				return fmt.Errorf(fmtErr, x, y)
+			}
+
+			pixels[y*tw+x] = uint8(palIdx)
+		}
+	}
+
+	block.PixelData = pixels
+	block.stale = false
+
+	if block.format == BlockFormatIsometric {
+		return block.EncodeIsometric()
+	}
+
+	return block.EncodeRLE()
+}
+
+// EncodeBlocks re-encodes every block of the tile from its PixelData into
+// EncodedData, according to the block's format, and updates Length. Blocks
+// which have not been decoded are left untouched, as their EncodedData is
+// already up to date.
+func (t *Tile) EncodeBlocks() error {
+	for blockIdx, block := range t.Blocks {
+		if block.PixelData == nil {
+			continue
+		}
+
+		var err error
+
+		switch block.format {
+		case BlockFormatIsometric:
+			err = block.EncodeIsometric()
+		default:
+			err = block.EncodeRLE()
+		}
+
+		if err != nil {
+			return fmt.Errorf("encoding block %d: %v", blockIdx, err)
+		}
+	}
+
+	return nil
+}
+
+// EncodeIsometric encodes the isometric diamond of the block's PixelData into
+// EncodedData, and updates Length.
+func (block *Block) EncodeIsometric() error {
+	if err := block.checkEncodable(); err != nil {
+		return err
+	}
+
+	encoded := make([]byte, blockDataLength)
+
+	_ = walkIsometric(func(x, y int32, dataIdx int) error {
+		encoded[dataIdx] = block.pixelAt(x, y)
+		return nil
+	})
+
+	block.EncodedData = encoded
+	block.Length = int32(len(encoded))
+
+	return nil
+}
+
+// EncodeRLE run-length encodes the block's PixelData into EncodedData, and
+// updates Length. Each row is encoded as pairs of (pixels to skip, run length)
+// followed by the run's palette indices, and is terminated by a (0, 0) pair.
+// Rows after the last non-transparent row are omitted.
+func (block *Block) EncodeRLE() error {
+	if err := block.checkEncodable(); err != nil {
+		return err
+	}
+
+	encoded := make([]byte, 0)
+	pendingRows := 0
+
+	for y := int32(0); y < blockHeightRLE; y++ {
+		row := make([]byte, 0)
+		x := int32(0)
+
+		for runStart := int32(0); runStart < blockWidth; {
+			if block.pixelAt(runStart, y) == 0 {
+				runStart++
+				continue
+			}
+
+			runEnd := runStart
+			for runEnd < blockWidth && block.pixelAt(runEnd, y) != 0 {
+				runEnd++
+			}
+
+			row = append(row, byte(runStart-x), byte(runEnd-runStart))
+
+			for px := runStart; px < runEnd; px++ {
+				row = append(row, block.pixelAt(px, y))
+			}
+
+			x, runStart = runEnd, runEnd
+		}
+
+		if len(row) == 0 {
+			pendingRows++
+			continue
+		}
+
+		// every row before this one is terminated with a (0, 0) pair
+		encoded = append(encoded, make([]byte, 2*pendingRows)...)
+		encoded = append(encoded, row...)
+		pendingRows = 1
+	}
+
+	if len(encoded) > 0 {
+		encoded = append(encoded, 0, 0)
+	}
+
+	block.EncodedData = encoded
+	block.Length = int32(len(encoded))
+
+	return nil
+}
+
+// checkEncodable verifies that the block's PixelData can be encoded
+func (block *Block) checkEncodable() error {
+	if block.tile == nil {
+		return errors.New("block does not belong to a tile")
+	}
+
+	if block.stale {
+		return fmt.Errorf("block %d: pixel data is stale, decode the block first", block.index())
+	}
+
+	return nil
+}
+
+// pixelAt returns the palette index of the pixel at the block-local position
+// (x, y) in PixelData, or 0 if the position is outside of the pixel buffer.
+func (block *Block) pixelAt(x, y int32) uint8 {
+	w := block.tile.Width
+	tileX := int32(block.X) + x
+	tileY := int32(block.Y) + y + block.tile.yOffset()
+
+	if tileX < 0 || tileX >= w || tileY < 0 {
+		return 0
+	}
+
+	offset := tileY*w + tileX
+	if offset >= int32(len(block.PixelData)) {
+		return 0
+	}
+
+	return block.PixelData[offset]
+}
+
+// byteWriter appends little-endian values to a byte slice
+type byteWriter struct {
+	data []byte
+}
+
+func (w *byteWriter) byte(b byte) {
+	w.data = append(w.data, b)
+}
+
+func (w *byteWriter) int16(v int16) {
+	w.uint16(uint16(v))
+}
+
+func (w *byteWriter) uint16(v uint16) {
+	w.data = binary.LittleEndian.AppendUint16(w.data, v)
+}
+
+func (w *byteWriter) int32(v int32) {
+	w.data = binary.LittleEndian.AppendUint32(w.data, uint32(v))
+}
+
+func (w *byteWriter) skip(n int) {
+	w.data = append(w.data, make([]byte, n)...)
+}
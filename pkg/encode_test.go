@@ -0,0 +1,267 @@
+package pkg
+
+import (
+	"bytes"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+func testEncodableDT1() *DT1 {
+	d := &DT1{}
+
+	tile := &Tile{
+		dt1:                d,
+		Direction:          3,
+		RoofHeight:         2,
+		MaterialFlags:      NewMaterialFlags(0x0041),
+		Height:             -80,
+		Width:              160,
+		Type:               1,
+		Style:              2,
+		Sequence:           3,
+		RarityFrameIndex:   4,
+		blockHeaderPointer: fileHeaderLength + tileHeaderLength,
+		blockHeaderSize:    2 * blockHeaderLength,
+	}
+	tile.SubTileFlags[3] = NewSubTileFlags(0x09)
+
+	tile.Blocks = []*Block{
+		{
+			tile:        tile,
+			X:           16,
+			Y:           -32,
+			GridX:       2,
+			GridY:       1,
+			format:      BlockFormatRLE,
+			Length:      6,
+			FileOffset:  2 * blockHeaderLength,
+			EncodedData: []byte{1, 2, 7, 8, 0, 0},
+		},
+		{
+			tile:        tile,
+			X:           0,
+			Y:           0,
+			format:      BlockFormatIsometric,
+			Length:      blockDataLength,
+			FileOffset:  2*blockHeaderLength + 6,
+			EncodedData: bytes.Repeat([]byte{5}, blockDataLength),
+		},
+	}
+
+	d.Tiles = []*Tile{tile}
+
+	return d
+}
+
+func TestToBytesRoundTrip(t *testing.T) {
+	assert := testify.New(t)
+
+	original := testEncodableDT1()
+
+	data, err := original.ToBytes()
+	assert.NoError(err)
+
+	decoded, err := FromBytes(data)
+	assert.NoError(err)
+	assert.Len(decoded.Tiles, 1)
+
+	want, got := original.Tiles[0], decoded.Tiles[0]
+	assert.Equal(want.Direction, got.Direction)
+	assert.Equal(want.MaterialFlags, got.MaterialFlags)
+	assert.Equal(want.Height, got.Height)
+	assert.Equal(want.RarityFrameIndex, got.RarityFrameIndex)
+	assert.Equal(want.SubTileFlags, got.SubTileFlags)
+	assert.Len(got.Blocks, 2)
+
+	for idx := range want.Blocks {
+		assert.Equal(want.Blocks[idx].X, got.Blocks[idx].X)
+		assert.Equal(want.Blocks[idx].Y, got.Blocks[idx].Y)
+		assert.Equal(want.Blocks[idx].GridX, got.Blocks[idx].GridX)
+		assert.Equal(want.Blocks[idx].Format(), got.Blocks[idx].Format())
+		assert.Equal(want.Blocks[idx].EncodedData, got.Blocks[idx].EncodedData)
+	}
+
+	reencoded, err := decoded.ToBytes()
+	assert.NoError(err)
+	assert.Equal(data, reencoded)
+}
+
+func TestToBytesReproducesBenchmarkFile(t *testing.T) {
+	assert := testify.New(t)
+
+	data, err := os.ReadFile(benchmarkFile)
+	assert.NoError(err)
+
+	decoded, err := FromBytes(data)
+	assert.NoError(err)
+
+	encoded, err := decoded.ToBytes()
+	assert.NoError(err)
+	assert.True(bytes.Equal(data, encoded), "the file must be reproduced exactly")
+}
+
+func TestToBytesMissingEncodedData(t *testing.T) {
+	assert := testify.New(t)
+
+	buf := &bytes.Buffer{}
+	assert.NoError(testEncodableDT1().ExportJSON(buf, false))
+
+	loaded, err := LoadFromJSON(buf)
+	assert.NoError(err)
+
+	_, err = loaded.ToBytes()
+	assert.ErrorContains(err, "tile 0 block 0, tile 0 block 1")
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	assert := testify.New(t)
+
+	d := testEncodableDT1()
+
+	for _, block := range d.Tiles[0].Blocks {
+		assert.NoError(block.decode())
+
+		original := append([]byte{}, block.PixelData...)
+
+		if block.Format() == BlockFormatIsometric {
+			assert.NoError(block.EncodeIsometric())
+		} else {
+			assert.NoError(block.EncodeRLE())
+		}
+
+		assert.NoError(block.decode())
+		assert.Equal(original, block.PixelData)
+	}
+}
+
+func TestNormalizeBlockOffsets(t *testing.T) {
+	assert := testify.New(t)
+
+	d := testEncodableDT1()
+	tile := d.Tiles[0]
+	tile.Blocks[0].EncodedData = []byte{0, 3, 1, 2, 3, 0, 0}
+
+	assert.NoError(d.NormalizeBlockOffsets())
+	assert.Equal(int32(fileHeaderLength+tileHeaderLength), tile.blockHeaderPointer)
+	assert.Equal(int32(7), tile.Blocks[0].Length)
+	assert.Equal(int32(2*blockHeaderLength+7), tile.Blocks[1].FileOffset)
+
+	data, err := d.ToBytes()
+	assert.NoError(err)
+
+	decoded, err := FromBytes(data)
+	assert.NoError(err)
+	assert.Equal(tile.Blocks[0].EncodedData, decoded.Tiles[0].Blocks[0].EncodedData)
+	assert.Equal(tile.Blocks[1].EncodedData, decoded.Tiles[0].Blocks[1].EncodedData)
+
+	tile.Blocks[1].EncodedData = nil
+	assert.ErrorContains(d.NormalizeBlockOffsets(), "tile 0, block 1")
+}
+
+func TestNormalizeBlockOffsetsRepeatedTile(t *testing.T) {
+	assert := testify.New(t)
+
+	d := NewTestDT1(2, 1)
+	d.Tiles = append(d.Tiles, d.Tiles[0])
+	assert.NoError(d.NormalizeBlockOffsets())
+
+	// the tile is laid out once, after the three tile headers
+	headersEnd := int32(fileHeaderLength + 3*tileHeaderLength)
+	blockSize := int32(blockHeaderLength + blockDataLength)
+	assert.Equal(headersEnd, d.Tiles[0].blockHeaderPointer)
+	assert.Equal(headersEnd+blockSize, d.Tiles[1].blockHeaderPointer)
+
+	data, err := d.ToBytes()
+	assert.NoError(err)
+	assert.Len(data, int(headersEnd+2*blockSize))
+
+	decoded, err := FromBytes(data)
+	assert.NoError(err)
+	assert.Equal(decoded.Tiles[0].blockHeaderPointer, decoded.Tiles[2].blockHeaderPointer)
+	assert.True(decoded.Tiles[2].Equals(d.Tiles[0]))
+}
+
+func TestNewTestDT1RoundTrip(t *testing.T) {
+	assert := testify.New(t)
+
+	d := NewTestDT1(3, 4)
+	assert.NoError(d.DecodeAllGraphics())
+
+	data, err := d.ToBytes()
+	assert.NoError(err)
+
+	decoded, err := FromBytes(data)
+	assert.NoError(err)
+	assert.True(decoded.Diff(d).IsEmpty())
+}
+
+func TestWriteToFile(t *testing.T) {
+	assert := testify.New(t)
+
+	path := filepath.Join(t.TempDir(), "test.dt1")
+	d := NewTestDT1(2, 3)
+
+	assert.NoError(d.WriteToFile(path))
+
+	loaded, err := FromFile(path)
+	assert.NoError(err)
+	assert.True(loaded.Diff(d).IsEmpty())
+}
+
+func TestRebuildFromTiles(t *testing.T) {
+	assert := testify.New(t)
+
+	d := NewTestDT1(3, 2)
+	d.SetPalette(make(color.Palette, 256))
+	pointer := d.Tiles[2].blockHeaderPointer
+
+	rebuilt, err := d.RebuildFromTiles([]*Tile{d.Tiles[2], d.Tiles[0]})
+	assert.NoError(err)
+	assert.Len(rebuilt.Tiles, 2)
+	assert.Equal(d.Palette(), rebuilt.Palette())
+
+	assert.NotSame(d.Tiles[2], rebuilt.Tiles[0])
+	assert.Same(rebuilt, rebuilt.Tiles[0].ParentDT1())
+	assert.True(rebuilt.Tiles[0].Equals(d.Tiles[2]))
+	assert.Equal(pointer, d.Tiles[2].blockHeaderPointer, "the source tiles must not change")
+	assert.Equal(int32(fileHeaderLength+2*tileHeaderLength), rebuilt.Tiles[0].blockHeaderPointer)
+
+	data, err := rebuilt.ToBytes()
+	assert.NoError(err)
+
+	decoded, err := FromBytes(data)
+	assert.NoError(err)
+	assert.True(decoded.Tiles[1].Equals(d.Tiles[0]))
+
+	d.Tiles[1].Blocks[0].EncodedData = nil
+	_, err = d.RebuildFromTiles(d.Tiles)
+	assert.Error(err)
+}
+
+func TestTileEncodeBlocks(t *testing.T) {
+	assert := testify.New(t)
+
+	tile := testEncodableDT1().Tiles[0]
+	rle, isometric := tile.Blocks[0], tile.Blocks[1]
+	isometricData := isometric.EncodedData
+
+	// blocks which are not decoded are left untouched
+	assert.NoError(tile.EncodeBlocks())
+	assert.Equal([]byte{1, 2, 7, 8, 0, 0}, rle.EncodedData)
+
+	assert.NoError(rle.DecodeRLE())
+	rle.PixelData[16] = 9
+	rle.PixelData[20] = 4
+
+	assert.NoError(tile.EncodeBlocks())
+	assert.Equal([]byte{0, 3, 9, 7, 8, 1, 1, 4, 0, 0}, rle.EncodedData)
+	assert.Equal(int32(10), rle.Length)
+	assert.Equal(isometricData, isometric.EncodedData)
+
+	rle.stale = true
+	assert.Error(tile.EncodeBlocks())
+}
@@ -0,0 +1,28 @@
+package pkg
+
+import (
+	"os"
+)
+
+// FromFile loads a DT1 from the file at the given path
+func FromFile(path string) (*DT1, error) {
+	fileData, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return FromBytes(fileData)
+}
+
+// WriteToFile encodes the DT1 with ToBytes and writes it to the file at the
+// given path, creating or truncating the file.
+func (d *DT1) WriteToFile(path string) error {
+	const fileMode = 0o644
+
+	data, err := d.ToBytes()
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, fileMode)
+}
@@ -0,0 +1,180 @@
+package pkg
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fuzzSeeds returns the DT1 files in testdata, which include the generated
+// benchmarkFile, and the binary encoding of a few small synthetic DT1 files.
+// DT1 files extracted from the game can be copied to testdata to seed the
+// fuzzers with them, they are not committed as they can not be licensed.
+func fuzzSeeds(f *testing.F) [][]byte {
+	paths, err := filepath.Glob("testdata/*.dt1")
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	seeds := make([][]byte, 0, len(paths))
+
+	for _, path := range paths {
+		fileData, err := os.ReadFile(path)
+		if err != nil {
+			f.Fatal(err)
+		}
+
+		seeds = append(seeds, fileData)
+	}
+
+	for _, d := range []*DT1{testEncodableDT1(), NewTestDT1(1, 1), NewTestDT1(0, 0)} {
+		data, err := d.ToBytes()
+		if err != nil {
+			f.Fatal(err)
+		}
+
+		seeds = append(seeds, data)
+	}
+
+	return seeds
+}
+
+func FuzzFromBytes(f *testing.F) {
+	for _, seed := range fuzzSeeds(f) {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		defer func() {
+			if r := recover(); r != nil {
+				t.Fatalf("FromBytes panicked: %v", r)
+			}
+		}()
+
+		_, _ = FromBytes(data)
+	})
+}
+
+// FuzzRoundTrip decodes each input, encodes the result with ToBytes and
+// decodes that again; both decoded DT1s must have equal tiles. A canonical
+// layout must be reproduced exactly, only other layouts are normalized first.
+// Encoding the second DT1 must yield the same bytes again.
+func FuzzRoundTrip(f *testing.F) {
+	for _, seed := range fuzzSeeds(f) {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		decoded, err := FromBytes(data)
+		if err != nil {
+			return
+		}
+
+		canonical := !hasNonCanonicalLayout(decoded)
+
+		if !canonical {
+			// ToBytes writes the stored offsets, so these layouts are
+			// rewritten compactly first
+			if err := decoded.NormalizeBlockOffsets(); err != nil {
+				t.Fatalf("normalizing the block offsets failed: %v", err)
+			}
+		}
+
+		encoded, err := decoded.ToBytes()
+		if err != nil {
+			t.Fatalf("encoding the decoded DT1 failed: %v", err)
+		}
+
+		redecoded, err := FromBytes(encoded)
+		if err != nil {
+			t.Fatalf("decoding the encoded DT1 failed: %v", err)
+		}
+
+		if len(redecoded.Tiles) != len(decoded.Tiles) {
+			t.Fatalf("expected %d tiles, got %d", len(decoded.Tiles), len(redecoded.Tiles))
+		}
+
+		for idx, tile := range decoded.Tiles {
+			if !tile.Equals(redecoded.Tiles[idx]) {
+				t.Fatalf("tile %d changed in the round trip", idx)
+			}
+
+			if canonical && !sameLayout(tile, redecoded.Tiles[idx]) {
+				t.Fatalf("the layout of tile %d changed in the round trip", idx)
+			}
+		}
+
+		reencoded, err := redecoded.ToBytes()
+		if err != nil {
+			t.Fatalf("encoding the redecoded DT1 failed: %v", err)
+		}
+
+		if !bytes.Equal(encoded, reencoded) {
+			t.Fatal("encoding the redecoded DT1 changed its bytes")
+		}
+	})
+}
+
+// sameLayout returns true if both tiles store their block headers and block
+// data at the same offsets
+func sameLayout(a, b *Tile) bool {
+	if a.blockHeaderPointer != b.blockHeaderPointer || len(a.Blocks) != len(b.Blocks) {
+		return false
+	}
+
+	for idx, block := range a.Blocks {
+		if block.FileOffset != b.Blocks[idx].FileOffset {
+			return false
+		}
+	}
+
+	return true
+}
+
+// hasNonCanonicalLayout returns true if FromBytes accepted a layout which
+// ToBytes can not reproduce: block headers which overlap the tile headers
+// (the tile headers may be stored anywhere in the file, ToBytes always writes
+// them after the file header), negative block offsets, or block headers and
+// data of different tiles overlapping each other.
+func hasNonCanonicalLayout(d *DT1) bool {
+	tileDataEnd := int64(fileHeaderLength + len(d.Tiles)*tileHeaderLength)
+
+	type region struct{ start, end int64 }
+
+	regions := make([]region, 0)
+
+	for _, tile := range d.Tiles {
+		if len(tile.Blocks) == 0 {
+			continue
+		}
+
+		headersStart := int64(tile.blockHeaderPointer)
+		headersEnd := headersStart + int64(len(tile.Blocks)*blockHeaderLength)
+
+		if headersStart < tileDataEnd {
+			return true
+		}
+
+		regions = append(regions, region{headersStart, headersEnd})
+
+		for _, block := range tile.Blocks {
+			if block.FileOffset < 0 {
+				return true
+			}
+
+			dataStart := headersStart + int64(block.FileOffset)
+			regions = append(regions, region{dataStart, dataStart + int64(block.Length)})
+		}
+	}
+
+	for i, a := range regions {
+		for _, b := range regions[i+1:] {
+			if a.start < b.end && b.start < a.end {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -1,49 +1,246 @@
 package pkg
 
-import "image"
+import (
+	"errors"
+	"fmt"
+	"image"
+)
 
-// DecodeTileGfxData decodes tile graphics data for a slice of dt1 blocks
-func (d *DT1) decodeTileGraphics() {
-	yOffset := d.determineYOffset()
+// DecodeAllGraphics decodes the graphics data of all blocks of all tiles,
+// populating their PixelData. The first error encountered is returned, along
+// with the index of the tile and block which could not be decoded.
+func (d *DT1) DecodeAllGraphics() error {
+	for tileIdx, tile := range d.Tiles {
+		for _, block := range tile.Blocks {
+			if err := block.decode(); err != nil {
+				return fmt.Errorf("tile %d: %v", tileIdx, err)
+			}
+		}
+	}
+
+	return nil
+}
 
+// AllTilesDecoded returns true if all blocks of every tile have been decoded,
+// see Tile.AllBlocksDecoded.
+func (d *DT1) AllTilesDecoded() bool {
 	for _, tile := range d.Tiles {
-		tw, th := tile.Width, tile.Height
-		if th < 0 {
-			th *= -1
+		if !tile.AllBlocksDecoded() {
+			return false
 		}
+	}
 
-		for _, block := range tile.Blocks {
-			block.PixelData = make([]byte, tw*th)
+	return true
+}
+
+// AllBlocksDecoded returns true if every block of the tile has PixelData which
+// is not stale, meaning the tile can be rendered without decoding.
+func (t *Tile) AllBlocksDecoded() bool {
+	for _, block := range t.Blocks {
+		if block.PixelData == nil || block.stale {
+			return false
+		}
+	}
+
+	return true
+}
+
+// yOffset is the vertical offset applied to every block of the tile, so that
+// blocks with a negative Y (such as the blocks of wall tiles) are decoded
+// into the tile pixel buffer.
+func (t *Tile) yOffset() int32 {
+	var tileYMinimum int32
+
+	for _, block := range t.Blocks {
+		tileYMinimum = MinInt32(tileYMinimum, int32(block.Y))
+	}
+
+	return AbsInt32(tileYMinimum)
+}
+
+// decode decodes the block's encoded data into PixelData, which is a buffer of
+// palette indices with the same dimensions as the parent tile. The offsets are
+// validated before PixelData is touched.
+func (block *Block) decode() error {
+	if block.tile == nil {
+		return errors.New("block does not belong to a tile")
+	}
+
+	tw, th := block.tile.Width, AbsInt32(block.tile.Height)
+	yOffset := block.tile.yOffset()
+
+	if err := block.ValidateOffsets(tw, th); err != nil {
+		return err
+	}
+
+	block.PixelData = make([]byte, tw*th)
+
+	switch block.format {
+	case BlockFormatIsometric:
+		block.decodeIsometric(tw, yOffset)
+	case BlockFormatRLE:
+		block.decodeRunLengthEncoded(tw, yOffset)
+	}
+
+	block.image = image.NewRGBA(image.Rect(0, 0, int(tw), int(th)))
+	block.stale = false
+
+	return nil
+}
+
+// DecodeIsometric decodes the block's EncodedData as an isometric block into
+// PixelData. The block must belong to a tile, whose dimensions determine the
+// size of PixelData. An error is returned, and the block is left unchanged, if
+// the block is not in the isometric format.
+func (block *Block) DecodeIsometric() error {
+	return block.decodeAs(BlockFormatIsometric)
+}
 
-			if block.format == BlockFormatIsometric {
-				block.decodeIsometric(tw, yOffset)
+// DecodeRLE decodes the block's EncodedData as a run-length encoded block into
+// PixelData. The block must belong to a tile, whose dimensions determine the
+// size of PixelData. An error is returned, and the block is left unchanged, if
+// the block is not in the RLE format.
+func (block *Block) DecodeRLE() error {
+	return block.decodeAs(BlockFormatRLE)
+}
+
+func (block *Block) decodeAs(format BlockDataFormat) error {
+	if block.format != format {
+		const fmtErr = "block %d: can not decode %s data as %s"
+		return fmt.Errorf(fmtErr, block.index(), block.format, format)
+	}
+
+	return block.decode()
+}
+
+// ensureDecoded decodes the block if it has not been decoded yet, or if its
+// PixelData is stale.
+func (block *Block) ensureDecoded() error {
+	if block.PixelData != nil && !block.stale {
+		return nil
+	}
+
+	return block.decode()
+}
+
+// ValidateOffsets verifies that decoding the block into a pixel buffer with
+// the given tile dimensions would not write out of bounds, and that the
+// encoded data is long enough to be decoded.
+func (block *Block) ValidateOffsets(tileWidth, tileHeight int32) error {
+	var yOffset int32
+
+	if block.tile != nil {
+		yOffset = block.tile.yOffset()
+	}
+
+	return block.validateOffsets(tileWidth, tileHeight, yOffset)
+}
 
-				continue
+// validateOffsets is ValidateOffsets with an explicit vertical tile offset
+func (block *Block) validateOffsets(tileWidth, tileHeight, yOffset int32) error {
+	bufferLength := tileWidth * tileHeight
+
+	return block.walkPixels(func(x, y int32, _ int) error {
+		tileX := int32(block.X) + x
+		offset := (int32(block.Y)+y+yOffset)*tileWidth + tileX
+
+		if tileX < 0 || tileX >= tileWidth || offset < 0 || offset >= bufferLength {
+			const fmtErr = "block %d: pixel offset %d is out of bounds for a %dx%d tile"
+			return fmt.Errorf(fmtErr, block.index(), offset, tileWidth, tileHeight)
+		}
+
+		return nil
+	})
+}
+
+// walkPixels walks the encoded data, calling fn with the block-local position
+// of every pixel and the index of its palette index within EncodedData.
+func (block *Block) walkPixels(fn func(x, y int32, dataIdx int) error) error {
+	const fmtErr = "block %d: encoded data is truncated at byte %d"
+
+	if block.format == BlockFormatIsometric {
+		if len(block.EncodedData) < blockDataLength {
+			return fmt.Errorf(fmtErr, block.index(), len(block.EncodedData))
+		}
+
+		return walkIsometric(fn)
+	}
+
+	var x, y int32
+
+	idx := 0
+
+	for length := block.Length; length > 0; {
+		if idx+1 >= len(block.EncodedData) {
+			return fmt.Errorf(fmtErr, block.index(), idx)
+		}
+
+		b1, b2 := block.EncodedData[idx], block.EncodedData[idx+1]
+		idx += 2
+		length -= 2
+
+		if (b1 | b2) == 0 {
+			x = 0
+			y++
+
+			continue
+		}
+
+		x += int32(b1)
+		length -= int32(b2)
+
+		for ; b2 > 0; b2-- {
+			if idx >= len(block.EncodedData) {
+				return fmt.Errorf(fmtErr, block.index(), idx)
 			}
 
-			block.decodeRunLengthEncoded(tw, yOffset)
+			if err := fn(x, y, idx); err != nil {
+				return err
+			}
 
-			block.image = image.NewRGBA(image.Rect(0, 0, int(tw), int(th)))
+			idx++
+			x++
 		}
 	}
+
+	return nil
 }
 
-func (d *DT1) determineYOffset() (yOffset int32) {
-	for _, tile := range d.Tiles {
-		for blockIdx := range tile.Blocks {
-			block := tile.Blocks[blockIdx]
+// walkIsometric calls fn for every pixel of an isometric diamond, see the
+// diagram above decodeIsometric.
+func walkIsometric(fn func(x, y int32, dataIdx int) error) error {
+	xjump := []int32{14, 12, 10, 8, 6, 4, 2, 0, 2, 4, 6, 8, 10, 12, 14}
+	nbpix := []int32{4, 8, 12, 16, 20, 24, 28, 32, 28, 24, 20, 16, 12, 8, 4}
+
+	idx := 0
 
-			if int32(block.Y) < yOffset {
-				yOffset = int32(block.Y)
+	for y := range xjump {
+		for x := xjump[y]; x < xjump[y]+nbpix[y]; x++ {
+			if err := fn(x, int32(y), idx); err != nil {
+				return err
 			}
+
+			idx++
 		}
+	}
+
+	return nil
+}
+
+// index returns the index of the block within its parent tile, or -1 if the
+// block does not belong to a tile.
+func (block *Block) index() int {
+	if block.tile == nil {
+		return -1
+	}
 
-		if yOffset < 0 {
-			yOffset *= -1
+	for idx, other := range block.tile.Blocks {
+		if other == block {
+			return idx
 		}
 	}
 
-	return yOffset
+	return -1
 }
 
 /*
@@ -0,0 +1,158 @@
+package pkg
+
+import (
+	"bytes"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+// legacyYOffset is the DT1-wide vertical offset which was used to decode the
+// blocks of every tile before the offset became per tile. It is kept as it
+// was, including its dependence on the order of the tiles.
+func legacyYOffset(d *DT1) (yOffset int32) {
+	for _, tile := range d.Tiles {
+		for _, block := range tile.Blocks {
+			if int32(block.Y) < yOffset {
+				yOffset = int32(block.Y)
+			}
+		}
+
+		if yOffset < 0 {
+			yOffset *= -1
+		}
+	}
+
+	return yOffset
+}
+
+func yOffsetTestDT1(floor bool) *DT1 {
+	d := &DT1{}
+
+	if floor {
+		tile := &Tile{dt1: d, Width: 160, Height: 80}
+		tile.Blocks = []*Block{{
+			tile:        tile,
+			format:      BlockFormatIsometric,
+			Length:      256,
+			EncodedData: bytes.Repeat([]byte{3}, 256),
+		}}
+
+		d.Tiles = append(d.Tiles, tile)
+	}
+
+	for i := 0; i < 2; i++ {
+		wall := &Tile{dt1: d, Width: 160, Height: -96}
+		wall.Blocks = []*Block{{
+			tile:        wall,
+			X:           16,
+			Y:           -96,
+			format:      BlockFormatRLE,
+			Length:      6,
+			EncodedData: []byte{1, 2, byte(7 + i), 8, 0, 0},
+		}}
+
+		d.Tiles = append(d.Tiles, wall)
+	}
+
+	return d
+}
+
+func TestDecodeMatchesLegacyOffsetForWalls(t *testing.T) {
+	assert := testify.New(t)
+
+	d := yOffsetTestDT1(false)
+	legacy := legacyYOffset(d)
+
+	for _, tile := range d.Tiles {
+		block := tile.Blocks[0]
+
+		old := make([]byte, tile.Width*AbsInt32(tile.Height))
+		block.PixelData = old
+		block.decodeRunLengthEncoded(tile.Width, legacy)
+
+		block.decode()
+
+		assert.Equal(legacy, tile.yOffset())
+		assert.Equal(old, block.PixelData)
+	}
+}
+
+func TestDecodeUsesPerTileYOffset(t *testing.T) {
+	assert := testify.New(t)
+
+	d := yOffsetTestDT1(true)
+	floor := d.Tiles[0]
+	block := floor.Blocks[0]
+
+	// the legacy offset of the walls pushes the last of the 15 rows of the
+	// floor block past the end of the 80 pixel high floor tile
+	lastRow := 14 + legacyYOffset(d)
+	assert.GreaterOrEqual(lastRow, floor.Height)
+
+	assert.Equal(int32(0), floor.yOffset())
+
+	block.decode()
+
+	expected := make([]byte, floor.Width*floor.Height)
+	DecodeTileGfxData(floor.Blocks, &expected, floor.yOffset(), floor.Width)
+
+	assert.Equal(expected, block.PixelData)
+	assert.Equal(uint8(3), block.PixelData[14])
+	assert.Equal(uint8(3), block.PixelData[14*floor.Width+14])
+}
+
+func TestDecodeAllGraphics(t *testing.T) {
+	assert := testify.New(t)
+
+	d := NewTestDT1(2, 2)
+	wall := testEncodableDT1().Tiles[0]
+	wall.dt1 = d
+	d.Tiles = append(d.Tiles, wall)
+
+	assert.NoError(d.DecodeAllGraphics())
+
+	for _, tile := range d.Tiles {
+		for _, block := range tile.Blocks {
+			assert.Len(block.PixelData, int(tile.Width)*tile.WallHeight())
+		}
+	}
+
+	assert.Equal(uint8(7), wall.Blocks[0].PixelData[17])
+
+	d.Tiles[1].Blocks[1].X = 150
+	assert.ErrorContains(d.DecodeAllGraphics(), "tile 1")
+}
+
+func TestTileAllBlocksDecoded(t *testing.T) {
+	assert := testify.New(t)
+
+	tile := NewTestDT1(1, 2).Tiles[0]
+	assert.False(tile.AllBlocksDecoded())
+
+	assert.NoError(tile.Blocks[0].DecodeIsometric())
+	assert.False(tile.AllBlocksDecoded(), "one block is still encoded")
+
+	assert.NoError(tile.Blocks[1].DecodeIsometric())
+	assert.True(tile.AllBlocksDecoded())
+
+	assert.NoError(tile.Blocks[1].ShiftPosition(1, 0))
+	assert.False(tile.AllBlocksDecoded(), "shifting leaves stale pixel data")
+
+	assert.True((&Tile{}).AllBlocksDecoded())
+}
+
+func TestAllTilesDecoded(t *testing.T) {
+	assert := testify.New(t)
+
+	d := NewTestDT1(2, 1)
+	assert.False(d.AllTilesDecoded())
+
+	assert.NoError(d.Tiles[0].Blocks[0].DecodeIsometric())
+	assert.False(d.AllTilesDecoded())
+
+	assert.NoError(d.DecodeAllGraphics())
+	assert.True(d.AllTilesDecoded())
+
+	assert.True(NewTestDT1(0, 0).AllTilesDecoded())
+}
@@ -0,0 +1,167 @@
+package pkg
+
+// NewTestDT1 creates a synthetic DT1 with the given number of floor tiles,
+// each with blocksPerTile isometric blocks laid out over the 5x5 grid. The
+// pixels of each block form a checkerboard of two palette indices which are
+// derived from the tile and block index, so the data is deterministic and
+// tiles can be told apart. The block offsets are normalized, so the result
+// can be written with ToBytes.
+func NewTestDT1(tiles, blocksPerTile int) *DT1 {
+	const (
+		tileWidth  = 160
+		tileHeight = 80
+		numCells   = gridDivisionsXY * gridDivisionsXY
+	)
+
+	d := &DT1{Tiles: make([]*Tile, tiles)}
+
+	for tileIdx := range d.Tiles {
+		tile := &Tile{
+			dt1:    d,
+			Height: tileHeight,
+			Width:  tileWidth,
+			Type:   TileTypeFloor,
+			Style:  int32(tileIdx),
+			Blocks: make([]*Block, blocksPerTile),
+		}
+
+		for blockIdx := range tile.Blocks {
+			cell := blockIdx % numCells
+			gridX, gridY := cell%gridDivisionsXY, cell/gridDivisionsXY
+
+			// two distinct, non-transparent palette indices per block
+			base := uint8(1 + (tileIdx*blocksPerTile+blockIdx)%127*2)
+
+			encoded := make([]byte, blockDataLength)
+
+			_ = walkIsometric(func(x, y int32, dataIdx int) error {
+				encoded[dataIdx] = base + uint8((x+y)%2)
+				return nil
+			})
+
+			tile.Blocks[blockIdx] = &Block{
+				tile:        tile,
+				X:           int16(gridX * blockWidth),
+				Y:           int16(gridY * blockHeightIsometric),
+				GridX:       byte(gridX),
+				GridY:       byte(gridY),
+				format:      BlockFormatIsometric,
+				EncodedData: encoded,
+			}
+		}
+
+		d.Tiles[tileIdx] = tile
+	}
+
+	_ = d.NormalizeBlockOffsets()
+
+	return d
+}
+
+// newBenchmarkDT1 generates the DT1 stored in benchmarkFile. Besides floor
+// tiles it holds a roof tile, and wall and shadow tiles of differing negative
+// heights whose RLE blocks mix runs of different lengths, gaps and fully
+// transparent rows. Blocks without any pixels are left out, as in the DT1
+// files of the game.
+func newBenchmarkDT1() *DT1 {
+	const (
+		numFloors     = 8
+		blocksPerTile = gridDivisionsXY * gridDivisionsXY
+	)
+
+	d := NewTestDT1(numFloors+1, blocksPerTile)
+
+	roof := d.Tiles[numFloors]
+	roof.Type = TileTypeRoof
+	roof.RoofHeight = 48
+
+	wallPixels := func(seed int32) func(x, y int32) uint8 {
+		return func(x, y int32) uint8 {
+			if y%13 == 6 || (x/9+y/7+seed)%5 == 0 {
+				return 0
+			}
+
+			return uint8(1 + (x*3+y*5+seed)%254)
+		}
+	}
+
+	windowPixels := func(x, y int32) uint8 {
+		if x >= 64 && x < 96 && y >= 32 && y < 96 {
+			return 0
+		}
+
+		return wallPixels(3)(x, y)
+	}
+
+	shadowPixels := func(x, y int32) uint8 {
+		if x < y/2 || x >= y/2+96 {
+			return 0
+		}
+
+		return 1
+	}
+
+	d.Tiles = append(d.Tiles,
+		newRLETestTile(d, TileTypeLeftWall, -96, wallPixels(0)),
+		newRLETestTile(d, TileTypeRightWall, -128, wallPixels(1)),
+		newRLETestTile(d, TileTypeLeftNorthCornerWall, -160, windowPixels),
+		newRLETestTile(d, TileTypeLowerLeftWall, -64, wallPixels(2)),
+		newRLETestTile(d, TileTypeShadow, -96, shadowPixels),
+	)
+
+	for idx, tile := range d.Tiles[numFloors+1:] {
+		tile.Direction = int32(1 + idx%4)
+		tile.Style = int32(idx)
+		tile.MaterialFlags = NewMaterialFlags(uint16(1) << idx)
+		tile.SubTileFlags[idx] = NewSubTileFlags(0x01)
+	}
+
+	_ = d.NormalizeBlockOffsets()
+
+	return d
+}
+
+// newRLETestTile creates a tile of the given type and negative height, which
+// is covered by 32x32 RLE blocks encoding the palette indices returned by
+// pixel for each pixel of the tile.
+func newRLETestTile(d *DT1, tileType, height int32, pixel func(x, y int32) uint8) *Tile {
+	const tileWidth = 160
+
+	tile := &Tile{dt1: d, Type: tileType, Width: tileWidth, Height: height}
+	pixels := make([]byte, tileWidth*-height)
+
+	for y := int32(0); y < -height; y++ {
+		for x := int32(0); x < tileWidth; x++ {
+			pixels[y*tileWidth+x] = pixel(x, y)
+		}
+	}
+
+	for y := int32(0); y < -height; y += blockHeightRLE {
+		for x := int32(0); x < tileWidth; x += blockWidth {
+			tile.Blocks = append(tile.Blocks, &Block{
+				tile:      tile,
+				X:         int16(x),
+				Y:         int16(height + y),
+				GridX:     byte(x / blockWidth),
+				GridY:     byte(y / blockHeightRLE),
+				format:    BlockFormatRLE,
+				PixelData: pixels,
+			})
+		}
+	}
+
+	_ = tile.EncodeBlocks()
+
+	kept := make([]*Block, 0, len(tile.Blocks))
+
+	for _, block := range tile.Blocks {
+		if len(block.EncodedData) > 0 {
+			block.PixelData = nil
+			kept = append(kept, block)
+		}
+	}
+
+	tile.Blocks = kept
+
+	return tile
+}
@@ -0,0 +1,227 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+var (
+	_ json.Marshaler   = &DT1{}
+	_ json.Marshaler   = &Tile{}
+	_ json.Unmarshaler = &Tile{}
+)
+
+type dt1JSON struct {
+	Version  versionJSON `json:"version"`
+	NumTiles int         `json:"numTiles"`
+	Tiles    []tileJSON  `json:"tiles"`
+}
+
+type versionJSON struct {
+	Major int32 `json:"major"`
+	Minor int32 `json:"minor"`
+}
+
+type tileJSON struct {
+	Direction          int32       `json:"direction"`
+	RoofHeight         int16       `json:"roofHeight"`
+	MaterialFlags      uint16      `json:"materialFlags"`
+	Height             int32       `json:"height"`
+	Width              int32       `json:"width"`
+	Type               int32       `json:"type"`
+	Style              int32       `json:"style"`
+	Sequence           int32       `json:"sequence"`
+	RarityFrameIndex   int32       `json:"rarityFrameIndex"`
+	SubTileFlags       [25]uint8   `json:"subTileFlags"`
+	BlockHeaderPointer int32       `json:"blockHeaderPointer"`
+	BlockHeaderSize    int32       `json:"blockHeaderSize"`
+	Blocks             []blockJSON `json:"blocks"`
+}
+
+type blockJSON struct {
+	X           int16           `json:"x"`
+	Y           int16           `json:"y"`
+	GridX       byte            `json:"gridX"`
+	GridY       byte            `json:"gridY"`
+	Format      BlockDataFormat `json:"format"`
+	Length      int32           `json:"length"`
+	FileOffset  int32           `json:"fileOffset"`
+	EncodedData []byte          `json:"encodedData,omitempty"`
+}
+
+// MarshalJSON encodes the DT1 version, tile count and tile metadata as JSON.
+// The encoded and decoded pixel data of the blocks is left out.
+func (d *DT1) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.toJSON(false))
+}
+
+// ExportJSON writes the DT1 as an indented JSON document to w. If
+// includeRawData is true, the encoded data of every block is included as a
+// base64 string.
+func (d *DT1) ExportJSON(w io.Writer, includeRawData bool) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "\t")
+
+	return encoder.Encode(d.toJSON(includeRawData))
+}
+
+func (d *DT1) toJSON(includeRawData bool) dt1JSON {
+	major, minor := d.Version()
+
+	dj := dt1JSON{
+		Version:  versionJSON{Major: major, Minor: minor},
+		NumTiles: len(d.Tiles),
+		Tiles:    make([]tileJSON, len(d.Tiles)),
+	}
+
+	for idx, tile := range d.Tiles {
+		dj.Tiles[idx] = tile.toJSON(includeRawData)
+	}
+
+	return dj
+}
+
+// MarshalJSON encodes the tile metadata and the metadata of its blocks as
+// JSON. The encoded block data is left out, use WithRawData to include it.
+func (t *Tile) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.toJSON(false))
+}
+
+// UnmarshalJSON decodes tile metadata produced by MarshalJSON. The encoded
+// block data is only populated if it is present in the JSON document.
+func (t *Tile) UnmarshalJSON(data []byte) error {
+	var tj tileJSON
+
+	if err := json.Unmarshal(data, &tj); err != nil {
+		return err
+	}
+
+	t.fromJSON(tj)
+
+	return nil
+}
+
+// WithRawData returns a json.Marshaler for the tile which includes the encoded
+// block data, as base64 strings.
+func (t *Tile) WithRawData() json.Marshaler {
+	return tileWithRawData{tile: t}
+}
+
+type tileWithRawData struct {
+	tile *Tile
+}
+
+func (t tileWithRawData) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.tile.toJSON(true))
+}
+
+func (t *Tile) toJSON(includeRawData bool) tileJSON {
+	tj := tileJSON{
+		Direction:          t.Direction,
+		RoofHeight:         t.RoofHeight,
+		MaterialFlags:      t.MaterialFlags.Encode(),
+		Height:             t.Height,
+		Width:              t.Width,
+		Type:               t.Type,
+		Style:              t.Style,
+		Sequence:           t.Sequence,
+		RarityFrameIndex:   t.RarityFrameIndex,
+		BlockHeaderPointer: t.blockHeaderPointer,
+		BlockHeaderSize:    t.blockHeaderSize,
+		Blocks:             make([]blockJSON, len(t.Blocks)),
+	}
+
+	for idx, flags := range t.SubTileFlags {
+		tj.SubTileFlags[idx] = flags.Encode()
+	}
+
+	for idx, block := range t.Blocks {
+		bj := blockJSON{
+			X:          block.X,
+			Y:          block.Y,
+			GridX:      block.GridX,
+			GridY:      block.GridY,
+			Format:     block.format,
+			Length:     block.Length,
+			FileOffset: block.FileOffset,
+		}
+
+		if includeRawData {
+			bj.EncodedData = block.EncodedData
+		}
+
+		tj.Blocks[idx] = bj
+	}
+
+	return tj
+}
+
+func (t *Tile) fromJSON(tj tileJSON) {
+	t.Direction = tj.Direction
+	t.RoofHeight = tj.RoofHeight
+	t.MaterialFlags = NewMaterialFlags(tj.MaterialFlags)
+	t.Height = tj.Height
+	t.Width = tj.Width
+	t.Type = tj.Type
+	t.Style = tj.Style
+	t.Sequence = tj.Sequence
+	t.RarityFrameIndex = tj.RarityFrameIndex
+	t.blockHeaderPointer = tj.BlockHeaderPointer
+	t.blockHeaderSize = tj.BlockHeaderSize
+
+	for idx, flags := range tj.SubTileFlags {
+		t.SubTileFlags[idx] = NewSubTileFlags(flags)
+	}
+
+	t.Blocks = make([]*Block, len(tj.Blocks))
+
+	for idx, bj := range tj.Blocks {
+		t.Blocks[idx] = &Block{
+			tile:        t,
+			X:           bj.X,
+			Y:           bj.Y,
+			GridX:       bj.GridX,
+			GridY:       bj.GridY,
+			format:      bj.Format,
+			Length:      bj.Length,
+			FileOffset:  bj.FileOffset,
+			EncodedData: bj.EncodedData,
+		}
+	}
+}
+
+// LoadFromJSON reconstructs a DT1 from a JSON document produced by ExportJSON.
+// If the document does not include the raw block data, the blocks will have
+// nil EncodedData, and ToBytes will fail until the data is provided.
+func LoadFromJSON(r io.Reader) (*DT1, error) {
+	var dj dt1JSON
+
+	if err := json.NewDecoder(r).Decode(&dj); err != nil {
+		return nil, err
+	}
+
+	if dj.Version.Major != versionMajor || dj.Version.Minor != versionMinor {
+		const fmtErr = "expected to have a version of %d.%d, got %d.%d instead"
+		return nil, fmt.Errorf(fmtErr, versionMajor, versionMinor, dj.Version.Major, dj.Version.Minor)
+	}
+
+	if dj.NumTiles != len(dj.Tiles) {
+		const fmtErr = "expected %d tiles, got %d instead"
+		return nil, fmt.Errorf(fmtErr, dj.NumTiles, len(dj.Tiles))
+	}
+
+	d := &DT1{
+		Tiles: make([]*Tile, len(dj.Tiles)),
+	}
+
+	d.version.major, d.version.minor = dj.Version.Major, dj.Version.Minor
+
+	for idx, tj := range dj.Tiles {
+		tile := &Tile{dt1: d}
+		tile.fromJSON(tj)
+		d.Tiles[idx] = tile
+	}
+
+	return d, nil
+}
@@ -0,0 +1,45 @@
+package pkg
+
+import (
+	"encoding/json"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+func TestTileJSONRoundTrip(t *testing.T) {
+	assert := testify.New(t)
+
+	tile := &Tile{
+		Direction:     3,
+		RoofHeight:    -2,
+		MaterialFlags: NewMaterialFlags(0x0102),
+		Height:        -96,
+		Width:         160,
+		Type:          1,
+		Style:         4,
+		Sequence:      5,
+	}
+	tile.SubTileFlags[7] = NewSubTileFlags(0x21)
+	tile.Blocks = []*Block{
+		{tile: tile, X: 32, Y: -32, GridX: 1, format: BlockFormatRLE, Length: 4, EncodedData: []byte{0, 1, 9, 0}},
+	}
+
+	data, err := json.Marshal(tile)
+	assert.NoError(err)
+	assert.NotContains(string(data), "encodedData")
+
+	var decoded Tile
+	assert.NoError(json.Unmarshal(data, &decoded))
+	assert.Equal(tile.MaterialFlags, decoded.MaterialFlags)
+	assert.Equal(tile.SubTileFlags, decoded.SubTileFlags)
+	assert.Equal(tile.Height, decoded.Height)
+	assert.Len(decoded.Blocks, 1)
+	assert.Nil(decoded.Blocks[0].EncodedData)
+
+	data, err = json.Marshal(tile.WithRawData())
+	assert.NoError(err)
+	assert.NoError(json.Unmarshal(data, &decoded))
+	assert.Equal(tile.Blocks[0].EncodedData, decoded.Blocks[0].EncodedData)
+	assert.Same(&decoded, decoded.Blocks[0].tile)
+}
@@ -1,5 +1,10 @@
 package pkg
 
+import (
+	"image/color"
+	"strings"
+)
+
 // MaterialFlags represents the material flags. Lots of unknowns for now...
 type MaterialFlags struct {
 	Other        bool
@@ -30,3 +35,123 @@ func NewMaterialFlags(data uint16) MaterialFlags {
 		Snow:         data&0x0400 == 0x0400,
 	}
 }
+
+// Encode returns the material flags in their binary representation
+// nolint:gomnd // Binary values
+func (m MaterialFlags) Encode() uint16 {
+	var data uint16
+
+	flags := []struct {
+		set  bool
+		mask uint16
+	}{
+		{m.Other, 0x0001},
+		{m.Water, 0x0002},
+		{m.WoodObject, 0x0004},
+		{m.InsideStone, 0x0008},
+		{m.OutsideStone, 0x0010},
+		{m.Dirt, 0x0020},
+		{m.Sand, 0x0040},
+		{m.Wood, 0x0080},
+		{m.Lava, 0x0100},
+		{m.Snow, 0x0400},
+	}
+
+	for _, flag := range flags {
+		if flag.set {
+			data |= flag.mask
+		}
+	}
+
+	return data
+}
+
+// String returns the names of the set material flags, separated by "|", or
+// "None" if no flag is set.
+func (m MaterialFlags) String() string {
+	names := m.names()
+	if len(names) == 0 {
+		return "None"
+	}
+
+	return strings.Join(names, "|")
+}
+
+// names returns the names of the set material flags, in bit order
+func (m MaterialFlags) names() []string {
+	flags := []struct {
+		set  bool
+		name string
+	}{
+		{m.Other, "Other"},
+		{m.Water, "Water"},
+		{m.WoodObject, "WoodObject"},
+		{m.InsideStone, "InsideStone"},
+		{m.OutsideStone, "OutsideStone"},
+		{m.Dirt, "Dirt"},
+		{m.Sand, "Sand"},
+		{m.Wood, "Wood"},
+		{m.Lava, "Lava"},
+		{m.Snow, "Snow"},
+	}
+
+	names := make([]string, 0)
+
+	for _, flag := range flags {
+		if flag.set {
+			names = append(names, flag.name)
+		}
+	}
+
+	return names
+}
+
+// MaterialMap returns a map from material name, as used by
+// MaterialFlags.String, to the indices of the tiles which have that material
+// flag set. A tile with several materials is listed under each of them; tiles
+// without any material are not listed.
+func (d *DT1) MaterialMap() map[string][]int {
+	materials := make(map[string][]int)
+
+	for idx, tile := range d.Tiles {
+		for _, name := range tile.MaterialFlags.names() {
+			materials[name] = append(materials[name], idx)
+		}
+	}
+
+	return materials
+}
+
+// MaterialColor returns a representative color of the tile's material, for
+// debug overlays. If more than one material flag is set, the most visually
+// distinct material wins, in the order lava, water, snow, sand, dirt, wood,
+// wooden object, inside stone, outside stone and other. Returns false if no
+// material flag is set.
+// nolint:gomnd // RGB values
+func (t *Tile) MaterialColor() (color.RGBA, bool) {
+	m := t.MaterialFlags
+
+	materials := []struct {
+		set bool
+		col color.RGBA
+	}{
+		{m.Lava, color.RGBA{R: 230, G: 60, B: 20, A: 255}},
+		{m.Water, color.RGBA{R: 30, G: 90, B: 220, A: 255}},
+		{m.Snow, color.RGBA{R: 240, G: 245, B: 255, A: 255}},
+		{m.Sand, color.RGBA{R: 230, G: 210, B: 120, A: 255}},
+		{m.Dirt, color.RGBA{R: 120, G: 80, B: 40, A: 255}},
+		{m.Wood, color.RGBA{R: 160, G: 110, B: 60, A: 255}},
+		{m.WoodObject, color.RGBA{R: 100, G: 60, B: 30, A: 255}},
+		{m.InsideStone, color.RGBA{R: 110, G: 110, B: 110, A: 255}},
+		{m.OutsideStone, color.RGBA{R: 160, G: 160, B: 150, A: 255}},
+		{m.Other, color.RGBA{R: 200, G: 0, B: 200, A: 255}},
+	}
+
+	for _, material := range materials {
+		if material.set {
+			return material.col, true
+		}
+	}
+
+	return color.RGBA{}, false
+}
@@ -0,0 +1,30 @@
+package pkg
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+func TestMaterialMap(t *testing.T) {
+	assert := testify.New(t)
+
+	d := NewTestDT1(3, 1)
+	d.Tiles[0].MaterialFlags = NewMaterialFlags(0x0042)
+	d.Tiles[2].MaterialFlags = NewMaterialFlags(0x0002)
+
+	assert.Equal("Water|Sand", d.Tiles[0].MaterialFlags.String())
+	assert.Equal("None", d.Tiles[1].MaterialFlags.String())
+
+	assert.Equal(map[string][]int{
+		"Water": {0, 2},
+		"Sand":  {0},
+	}, d.MaterialMap())
+
+	col, ok := d.Tiles[0].MaterialColor()
+	assert.True(ok)
+	assert.NotZero(col.A)
+
+	_, ok = d.Tiles[1].MaterialColor()
+	assert.False(ok)
+}
@@ -0,0 +1,42 @@
+package pkg
+
+import "unsafe"
+
+// MemoryUsage is a breakdown of the memory used by the decoded structures of
+// a DT1, in bytes.
+type MemoryUsage struct {
+	EncodedData int64 // encoded block data
+	PixelData   int64 // decoded palette indices
+	ImageCaches int64 // RGBA images held by the blocks
+	Metadata    int64 // the DT1, tile and block structs themselves
+}
+
+// Total returns the sum of all memory usage categories
+func (m MemoryUsage) Total() int64 {
+	return m.EncodedData + m.PixelData + m.ImageCaches + m.Metadata
+}
+
+// Memory reports the live memory usage of the DT1 and all of its decoded
+// structures. Struct sizes are taken with unsafe.Sizeof, buffer sizes are the
+// lengths of the underlying slices.
+func (d *DT1) Memory() MemoryUsage {
+	usage := MemoryUsage{
+		Metadata: int64(unsafe.Sizeof(*d)),
+	}
+
+	for _, tile := range d.Tiles {
+		usage.Metadata += int64(unsafe.Sizeof(*tile))
+
+		for _, block := range tile.Blocks {
+			usage.Metadata += int64(unsafe.Sizeof(*block))
+			usage.EncodedData += int64(len(block.EncodedData))
+			usage.PixelData += int64(len(block.PixelData))
+
+			if block.image != nil {
+				usage.ImageCaches += int64(unsafe.Sizeof(*block.image)) + int64(len(block.image.Pix))
+			}
+		}
+	}
+
+	return usage
+}
@@ -0,0 +1,28 @@
+package pkg
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+func TestMemory(t *testing.T) {
+	assert := testify.New(t)
+
+	d := NewTestDT1(2, 3)
+
+	before := d.Memory()
+	assert.Equal(int64(2*3*blockDataLength), before.EncodedData)
+	assert.Zero(before.PixelData)
+	assert.Zero(before.ImageCaches)
+	assert.Positive(before.Metadata)
+
+	assert.NoError(d.DecodeAllGraphics())
+
+	after := d.Memory()
+	assert.Equal(before.EncodedData, after.EncodedData)
+	assert.Equal(before.Metadata, after.Metadata)
+	assert.Equal(int64(2*3*160*80), after.PixelData)
+	assert.Greater(after.ImageCaches, int64(2*3*160*80*4))
+	assert.Equal(after.EncodedData+after.PixelData+after.ImageCaches+after.Metadata, after.Total())
+}
@@ -0,0 +1,134 @@
+package pkg
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+const (
+	// defaultOverlayAlpha is the alpha used for overlays drawn on top of tiles
+	defaultOverlayAlpha = 128
+)
+
+// SubTilePassabilityImage renders the tile and draws a semi-transparent overlay
+// on each sub-tile cell: green if the sub-tile is walkable, red if it is
+// blocked. Cells are cellPx pixels square; if cellPx is not positive, the
+// cells are fit to the tile dimensions.
+func (t *Tile) SubTilePassabilityImage(cellPx int) *image.RGBA {
+	return t.SubTilePassabilityImageWithAlpha(cellPx, defaultOverlayAlpha)
+}
+
+// SubTilePassabilityImageWithAlpha is like SubTilePassabilityImage, but with a
+// configurable overlay alpha.
+func (t *Tile) SubTilePassabilityImageWithAlpha(cellPx int, alpha uint8) *image.RGBA {
+	canvas := t.canvas()
+
+	cellW, cellH := cellPx, cellPx
+	if cellPx <= 0 {
+		cellW, cellH = int(t.Width)/gridDivisionsXY, t.WallHeight()/gridDivisionsXY
+	}
+
+	passable := image.NewUniform(color.NRGBA{G: 255, A: alpha})
+	blocked := image.NewUniform(color.NRGBA{R: 255, A: alpha})
+
+	for idx, flags := range t.SubTileFlags {
+		col, row := idx%gridDivisionsXY, idx/gridDivisionsXY
+		cell := image.Rect(col*cellW, row*cellH, (col+1)*cellW, (row+1)*cellH)
+
+		overlay := passable
+		if flags.BlockWalk {
+			overlay = blocked
+		}
+
+		draw.Draw(canvas, cell.Intersect(canvas.Bounds()), overlay, image.Point{}, draw.Over)
+	}
+
+	return canvas
+}
+
+// canvas returns the composited tile image as an RGBA image which is safe to
+// draw on, or a blank image with the tile's dimensions if the tile has no
+// pixels.
+func (t *Tile) canvas() *image.RGBA {
+	rect := image.Rect(0, 0, int(t.Width), t.WallHeight())
+	canvas := image.NewRGBA(rect)
+
+	if img := t.Image(); img != nil {
+		draw.Draw(canvas, rect, img, img.Bounds().Min, draw.Src)
+	}
+
+	return canvas
+}
+
+// defaultGridColor is a semi-transparent red
+var defaultGridColor = color.NRGBA{R: 255, A: defaultOverlayAlpha}
+
+// GridImage renders the tile and draws lines on the boundaries of the 5x5
+// sub-tile grid, in semi-transparent red. Cells are cellSizePx pixels square;
+// if cellSizePx is not positive, the grid is fit to the tile dimensions.
+func (t *Tile) GridImage(cellSizePx int) *image.RGBA {
+	return t.GridImageWithColor(cellSizePx, defaultGridColor)
+}
+
+// GridImageWithColor is like GridImage, but with a configurable line color
+func (t *Tile) GridImageWithColor(cellSizePx int, lineColor color.Color) *image.RGBA {
+	canvas := t.canvas()
+
+	cellW, cellH := cellSizePx, cellSizePx
+	if cellSizePx <= 0 {
+		cellW, cellH = int(t.Width)/gridDivisionsXY, t.WallHeight()/gridDivisionsXY
+	}
+
+	drawGrid(canvas, cellW, cellH, lineColor)
+
+	return canvas
+}
+
+// ImageWithGridLines is an alias of GridImageWithColor with a cell size of 0,
+// which renders the tile with the 5x5 sub-tile grid fit to the tile dimensions
+// and drawn in the given color.
+//
+// Deprecated: Use GridImageWithColor(0, gridColor) instead.
+func (t *Tile) ImageWithGridLines(gridColor color.RGBA) image.Image {
+	return t.GridImageWithColor(0, gridColor)
+}
+
+// drawGrid draws the lines of a 5x5 grid with the given cell size onto canvas
+func drawGrid(canvas *image.RGBA, cellW, cellH int, lineColor color.Color) {
+	line := image.NewUniform(lineColor)
+	bounds := canvas.Bounds()
+
+	for i := 0; i <= gridDivisionsXY; i++ {
+		// the last line is drawn inside of the cell, so it is not clipped
+		x, y := i*cellW, i*cellH
+		if i == gridDivisionsXY {
+			x, y = x-1, y-1
+		}
+
+		vertical := image.Rect(x, 0, x+1, gridDivisionsXY*cellH)
+		horizontal := image.Rect(0, y, gridDivisionsXY*cellW, y+1)
+
+		draw.Draw(canvas, vertical.Intersect(bounds), line, image.Point{}, draw.Over)
+		draw.Draw(canvas, horizontal.Intersect(bounds), line, image.Point{}, draw.Over)
+	}
+}
+
+// ShadowMask returns the non-transparent pixels of a shadow tile as an alpha
+// mask, for renderers which blend shadows separately from the other tiles.
+// Returns nil if the tile is not a shadow tile.
+func (t *Tile) ShadowMask() *image.Alpha {
+	if t.Type != TileTypeShadow {
+		return nil
+	}
+
+	mask := image.NewAlpha(image.Rect(0, 0, int(t.Width), t.WallHeight()))
+
+	for idx, palIdx := range t.PaletteIndices() {
+		if palIdx != 0 {
+			mask.Pix[idx] = 255
+		}
+	}
+
+	return mask
+}
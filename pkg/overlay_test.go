@@ -0,0 +1,90 @@
+package pkg
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+func TestSubTilePassabilityImage(t *testing.T) {
+	assert := testify.New(t)
+
+	tile := NewTestDT1(1, 0).Tiles[0]
+	tile.SubTileFlags[24] = NewSubTileFlags(0x01)
+
+	img := tile.SubTilePassabilityImage(0)
+	assert.Equal(160, img.Bounds().Dx())
+	assert.Equal(80, img.Bounds().Dy())
+
+	for idx, flags := range tile.SubTileFlags {
+		col, row := idx%gridDivisionsXY, idx/gridDivisionsXY
+		pixel := img.RGBAAt(col*32+16, row*16+8)
+
+		assert.NotZero(pixel.A, "cell (%d, %d) has no overlay", col, row)
+
+		if flags.BlockWalk {
+			assert.NotZero(pixel.R, "cell (%d, %d) should be red", col, row)
+			assert.Zero(pixel.G, "cell (%d, %d) should be red", col, row)
+		} else {
+			assert.NotZero(pixel.G, "cell (%d, %d) should be green", col, row)
+			assert.Zero(pixel.R, "cell (%d, %d) should be green", col, row)
+		}
+	}
+}
+
+func TestTileImageWithGridLines(t *testing.T) {
+	assert := testify.New(t)
+
+	tile := NewTestDT1(1, 0).Tiles[0]
+	blue := color.RGBA{B: 255, A: 255}
+
+	img := tile.ImageWithGridLines(blue)
+	assert.Equal(tile.GridImageWithColor(0, blue), img)
+
+	rgba := img.(*image.RGBA)
+	assert.Equal(blue, rgba.RGBAAt(32, 5), "on a vertical line")
+	assert.Equal(blue, rgba.RGBAAt(5, 16), "on a horizontal line")
+	assert.Equal(blue, rgba.RGBAAt(159, 79), "the last lines are not clipped")
+	assert.Zero(rgba.RGBAAt(5, 5).A, "inside of a cell")
+}
+
+func TestTileGridImage(t *testing.T) {
+	assert := testify.New(t)
+
+	tile := NewTestDT1(1, 0).Tiles[0]
+
+	img := tile.GridImage(16)
+	assert.Equal(image.Rect(0, 0, 160, 80), img.Bounds())
+	assert.NotZero(img.RGBAAt(16, 3).A, "on a vertical line")
+	assert.NotZero(img.RGBAAt(79, 3).A, "on the last vertical line")
+	assert.NotZero(img.RGBAAt(40, 16).A, "on a horizontal line")
+	assert.Zero(img.RGBAAt(100, 16).A, "the lines span the grid only")
+	assert.Zero(img.RGBAAt(8, 8).A, "inside of a cell")
+
+	line := img.RGBAAt(16, 3)
+	assert.NotZero(line.R)
+	assert.Zero(line.G)
+	assert.Less(line.A, uint8(255), "the default color is semi-transparent")
+
+	fitted := tile.GridImage(0)
+	assert.NotZero(fitted.RGBAAt(32, 3).A)
+	assert.Zero(fitted.RGBAAt(16, 3).A)
+}
+
+func TestTileShadowMask(t *testing.T) {
+	assert := testify.New(t)
+
+	tile := testEncodableDT1().Tiles[0]
+	assert.Nil(tile.ShadowMask(), "only shadow tiles have a mask")
+
+	tile.Type = TileTypeShadow
+
+	mask := tile.ShadowMask()
+	assert.Equal(image.Rect(0, 0, 160, 80), mask.Bounds())
+	assert.Equal(uint8(255), mask.AlphaAt(17, 0).A)
+	assert.Equal(uint8(255), mask.AlphaAt(16, 32).A)
+	assert.Zero(mask.AlphaAt(16, 0).A)
+	assert.Zero(mask.AlphaAt(0, 79).A)
+}
@@ -0,0 +1,166 @@
+package pkg
+
+import (
+	"fmt"
+	"sort"
+)
+
+// TilesByDirection returns a map from Direction to the tiles with that
+// direction. The grouping is cached until the tiles are mutated; the returned
+// map is a copy and may be modified by the caller.
+func (d *DT1) TilesByDirection() map[int32][]*Tile {
+	return copyTileGroups(d.tilesByDirection())
+}
+
+// tilesByDirection returns the cached grouping of TilesByDirection, which must
+// not be modified.
+func (d *DT1) tilesByDirection() map[int32][]*Tile {
+	if d.cache.byDirection != nil {
+		return d.cache.byDirection
+	}
+
+	byDirection := make(map[int32][]*Tile)
+
+	for _, tile := range d.Tiles {
+		byDirection[tile.Direction] = append(byDirection[tile.Direction], tile)
+	}
+
+	d.cache.byDirection = byDirection
+
+	return byDirection
+}
+
+// TilesWithDirection returns the tiles with the given Direction, in file
+// order. The returned slice is a copy and may be modified by the caller.
+func (d *DT1) TilesWithDirection(dir int32) []*Tile {
+	return append([]*Tile(nil), d.tilesByDirection()[dir]...)
+}
+
+// UniqueDirections returns the distinct Direction values of the tiles, in
+// ascending order.
+func (d *DT1) UniqueDirections() []int32 {
+	byDirection := d.tilesByDirection()
+	directions := make([]int32, 0, len(byDirection))
+
+	for direction := range byDirection {
+		directions = append(directions, direction)
+	}
+
+	sort.Slice(directions, func(i, j int) bool {
+		return directions[i] < directions[j]
+	})
+
+	return directions
+}
+
+// GroupByStyle returns a map from Style to the tiles with that style. The
+// grouping is cached until the tiles are mutated; the returned map is a copy
+// and may be modified by the caller.
+func (d *DT1) GroupByStyle() map[int32][]*Tile {
+	if d.cache.byStyle != nil {
+		return copyTileGroups(d.cache.byStyle)
+	}
+
+	byStyle := make(map[int32][]*Tile)
+
+	for _, tile := range d.Tiles {
+		byStyle[tile.Style] = append(byStyle[tile.Style], tile)
+	}
+
+	d.cache.byStyle = byStyle
+
+	return copyTileGroups(byStyle)
+}
+
+// TilesOfType returns the tiles with the given Type, in file order. The
+// grouping by type is cached until the tiles are mutated; the returned slice is
+// a copy and may be modified by the caller.
+func (d *DT1) TilesOfType(tileType int32) []*Tile {
+	if d.cache.byType == nil {
+		byType := make(map[int32][]*Tile)
+
+		for _, tile := range d.Tiles {
+			byType[tile.Type] = append(byType[tile.Type], tile)
+		}
+
+		d.cache.byType = byType
+	}
+
+	return append([]*Tile(nil), d.cache.byType[tileType]...)
+}
+
+// AllSubTileFlags combines the sub-tile flags of all tiles of the given type,
+// yielding which collision bits are ever set for each sub-tile of that type.
+func (d *DT1) AllSubTileFlags(tileType int32) [25]SubTileFlags {
+	var combined [25]SubTileFlags
+
+	for _, tile := range d.Tiles {
+		if tile.Type != tileType {
+			continue
+		}
+
+		for idx := range combined {
+			combined[idx].Combine(tile.SubTileFlags[idx])
+		}
+	}
+
+	return combined
+}
+
+// TileByIndex returns the tile at index i, or an error if i is out of range
+func (d *DT1) TileByIndex(i int) (*Tile, error) {
+	if i < 0 || i >= len(d.Tiles) {
+		const fmtErr = "tile index %d is out of range, the DT1 has %d tiles"
+		return nil, fmt.Errorf(fmtErr, i, len(d.Tiles))
+	}
+
+	return d.Tiles[i], nil
+}
+
+// TilesInRange returns the tiles with indices from startIdx up to, but not
+// including, endIdx. An error is returned if the range is not within the
+// tiles. The returned slice shares its elements with Tiles.
+func (d *DT1) TilesInRange(startIdx, endIdx int) ([]*Tile, error) {
+	if startIdx < 0 || endIdx > len(d.Tiles) || startIdx > endIdx {
+		const fmtErr = "tile range [%d, %d) is out of range, the DT1 has %d tiles"
+		return nil, fmt.Errorf(fmtErr, startIdx, endIdx, len(d.Tiles))
+	}
+
+	return d.Tiles[startIdx:endIdx:endIdx], nil
+}
+
+// MaxTileDimensions returns the largest tile width and the largest absolute
+// tile height found across all tiles. The two values may come from different
+// tiles.
+func (d *DT1) MaxTileDimensions() (width, height int) {
+	for _, tile := range d.Tiles {
+		w, h := tile.Dimensions()
+		width, height = maxInt(width, w), maxInt(height, h)
+	}
+
+	return width, height
+}
+
+// ForEachBlock calls fn for every block of every tile, in order. fn receives
+// the index of the tile and the index of the block within that tile. Iteration
+// stops early if fn returns false.
+func (d *DT1) ForEachBlock(fn func(tileIdx, blockIdx int, b *Block) bool) {
+	for tileIdx, tile := range d.Tiles {
+		for blockIdx, block := range tile.Blocks {
+			if !fn(tileIdx, blockIdx, block) {
+				return
+			}
+		}
+	}
+}
+
+// MaxBlocksPerTile returns the largest number of blocks found in a single tile
+func (d *DT1) MaxBlocksPerTile() int {
+	most := 0
+
+	for _, tile := range d.Tiles {
+		most = maxInt(most, tile.NumBlocks())
+	}
+
+	return most
+}
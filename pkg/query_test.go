@@ -0,0 +1,186 @@
+package pkg
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+func TestCachedGroupsAreCopies(t *testing.T) {
+	assert := testify.New(t)
+
+	d := NewTestDT1(3, 1)
+	d.Tiles[1].Direction = 2
+
+	byDirection := d.TilesByDirection()
+	byDirection[0][0] = nil
+	delete(byDirection, 2)
+
+	assert.Equal([]*Tile{d.Tiles[0], d.Tiles[2]}, d.TilesByDirection()[0])
+	assert.Equal([]*Tile{d.Tiles[1]}, d.TilesWithDirection(2))
+
+	byStyle := d.GroupByStyle()
+	byStyle[0][0] = nil
+
+	assert.Equal([]*Tile{d.Tiles[0]}, d.GroupByStyle()[0])
+}
+
+func TestTilesByDirection(t *testing.T) {
+	assert := testify.New(t)
+
+	d := NewTestDT1(4, 1)
+	d.Tiles[1].Direction = 3
+	d.Tiles[3].Direction = 3
+
+	assert.Equal(map[int32][]*Tile{
+		0: {d.Tiles[0], d.Tiles[2]},
+		3: {d.Tiles[1], d.Tiles[3]},
+	}, d.TilesByDirection())
+
+	// the grouping is cached until the cache is invalidated
+	d.Tiles[0].Direction = 1
+	assert.Len(d.TilesByDirection(), 2)
+
+	d.InvalidateCache()
+	assert.Equal([]*Tile{d.Tiles[0]}, d.TilesByDirection()[1])
+	assert.Len(d.TilesByDirection(), 3)
+}
+
+func TestAllSubTileFlags(t *testing.T) {
+	assert := testify.New(t)
+
+	d := NewTestDT1(3, 0)
+	d.Tiles[0].Type = TileTypeLeftWall
+	d.Tiles[0].SubTileFlags[0] = NewSubTileFlags(0x01)
+	d.Tiles[1].Type = TileTypeLeftWall
+	d.Tiles[1].SubTileFlags[0] = NewSubTileFlags(0x02)
+	d.Tiles[1].SubTileFlags[24] = NewSubTileFlags(0x01)
+	d.Tiles[2].SubTileFlags[12] = NewSubTileFlags(0x01)
+
+	combined := d.AllSubTileFlags(TileTypeLeftWall)
+	assert.Equal(NewSubTileFlags(0x03), combined[0])
+	assert.Equal(NewSubTileFlags(0x01), combined[24])
+	assert.Equal(SubTileFlags{}, combined[12], "floor tiles must not be combined")
+
+	assert.Equal(NewSubTileFlags(0x01), d.AllSubTileFlags(TileTypeFloor)[12])
+	assert.Equal([25]SubTileFlags{}, d.AllSubTileFlags(TileTypeRoof))
+}
+
+func TestTileByIndex(t *testing.T) {
+	assert := testify.New(t)
+
+	d := NewTestDT1(2, 0)
+
+	tile, err := d.TileByIndex(1)
+	assert.NoError(err)
+	assert.Same(d.Tiles[1], tile)
+
+	for _, idx := range []int{-1, 2} {
+		tile, err = d.TileByIndex(idx)
+		assert.Error(err)
+		assert.Nil(tile)
+	}
+}
+
+func TestGroupByStyle(t *testing.T) {
+	assert := testify.New(t)
+
+	d := NewTestDT1(3, 0)
+	d.Tiles[2].Style = 0
+
+	assert.Equal(map[int32][]*Tile{
+		0: {d.Tiles[0], d.Tiles[2]},
+		1: {d.Tiles[1]},
+	}, d.GroupByStyle())
+
+	d.Tiles[1].Style = 0
+	d.InvalidateCache()
+	assert.Equal(map[int32][]*Tile{0: d.Tiles}, d.GroupByStyle())
+}
+
+func TestMaxTileDimensions(t *testing.T) {
+	assert := testify.New(t)
+
+	w, h := NewTestDT1(0, 0).MaxTileDimensions()
+	assert.Zero(w)
+	assert.Zero(h)
+
+	d := NewTestDT1(2, 0)
+	d.Tiles[0].Width = 192
+	d.Tiles[1].Height = -96
+
+	// the values may come from different tiles
+	w, h = d.MaxTileDimensions()
+	assert.Equal(192, w)
+	assert.Equal(96, h)
+}
+
+func TestForEachBlock(t *testing.T) {
+	assert := testify.New(t)
+
+	d := NewTestDT1(3, 2)
+	d.Tiles[1].Blocks = nil
+
+	visited := make([][2]int, 0)
+
+	d.ForEachBlock(func(tileIdx, blockIdx int, b *Block) bool {
+		assert.Same(d.Tiles[tileIdx].Blocks[blockIdx], b)
+		visited = append(visited, [2]int{tileIdx, blockIdx})
+
+		return true
+	})
+
+	assert.Equal([][2]int{{0, 0}, {0, 1}, {2, 0}, {2, 1}}, visited)
+
+	calls := 0
+
+	d.ForEachBlock(func(tileIdx, _ int, _ *Block) bool {
+		calls++
+		return tileIdx == 0
+	})
+
+	assert.Equal(3, calls, "iteration stops once fn returns false")
+}
+
+func TestUniqueDirections(t *testing.T) {
+	assert := testify.New(t)
+
+	d := NewTestDT1(4, 0)
+	d.Tiles[0].Direction = 4
+	d.Tiles[1].Direction = 1
+	d.Tiles[3].Direction = 4
+
+	assert.Equal([]int32{0, 1, 4}, d.UniqueDirections())
+	assert.Empty(NewTestDT1(0, 0).UniqueDirections())
+}
+
+func TestTilesWithDirection(t *testing.T) {
+	assert := testify.New(t)
+
+	d := NewTestDT1(3, 0)
+	d.Tiles[0].Direction = 2
+	d.Tiles[2].Direction = 2
+
+	assert.Equal([]*Tile{d.Tiles[0], d.Tiles[2]}, d.TilesWithDirection(2))
+	assert.Equal([]*Tile{d.Tiles[1]}, d.TilesWithDirection(0))
+	assert.Empty(d.TilesWithDirection(5))
+}
+
+func TestTilesOfType(t *testing.T) {
+	assert := testify.New(t)
+
+	d := NewTestDT1(3, 0)
+	d.Tiles[1].Type = TileTypeShadow
+
+	assert.Equal([]*Tile{d.Tiles[0], d.Tiles[2]}, d.TilesOfType(TileTypeFloor))
+	assert.Equal([]*Tile{d.Tiles[1]}, d.TilesOfType(TileTypeShadow))
+	assert.Empty(d.TilesOfType(TileTypeRoof))
+
+	// the result is a copy of the cached grouping
+	d.TilesOfType(TileTypeFloor)[0] = nil
+	assert.Same(d.Tiles[0], d.TilesOfType(TileTypeFloor)[0])
+
+	d.Tiles[0].Type = TileTypeShadow
+	d.InvalidateCache()
+	assert.Equal([]*Tile{d.Tiles[0], d.Tiles[1]}, d.TilesOfType(TileTypeShadow))
+}
@@ -77,3 +77,52 @@ func NewSubTileFlags(data byte) SubTileFlags {
 		Unknown3:        data&128 == 128,
 	}
 }
+
+// Encode returns the sub-tile flags in their binary representation
+//nolint:gomnd // binary flags
+func (s SubTileFlags) Encode() byte {
+	var data byte
+
+	flags := []bool{
+		s.BlockWalk,
+		s.BlockLOS,
+		s.BlockJump,
+		s.BlockPlayerWalk,
+		s.Unknown1,
+		s.BlockLight,
+		s.Unknown2,
+		s.Unknown3,
+	}
+
+	for bit, set := range flags {
+		if set {
+			data |= 1 << bit
+		}
+	}
+
+	return data
+}
+
+// ForEachSubTile calls fn for each of the 25 sub-tiles of the tile, in
+// column-major order. The flags are stored in row-major order, so the flags
+// of the sub-tile at (col, row) are t.SubTileFlags[row*5+col].
+func (t *Tile) ForEachSubTile(fn func(col, row int, flags SubTileFlags)) {
+	_ = t.ForEachSubTileE(func(col, row int, flags SubTileFlags) error {
+		fn(col, row, flags)
+		return nil
+	})
+}
+
+// ForEachSubTileE is like ForEachSubTile, but stops at, and returns, the first
+// error returned by fn.
+func (t *Tile) ForEachSubTileE(fn func(col, row int, flags SubTileFlags) error) error {
+	for col := 0; col < gridDivisionsXY; col++ {
+		for row := 0; row < gridDivisionsXY; row++ {
+			if err := fn(col, row, t.SubTileFlags[row*gridDivisionsXY+col]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package pkg
 
 import (
+	"errors"
 	"testing"
 
 	testify "github.com/stretchr/testify/assert"
@@ -22,3 +23,37 @@ func TestNewSubTile(t *testing.T) {
 		assert.Equal(i == 7, tile.Unknown3)
 	}
 }
+
+func TestTileForEachSubTile(t *testing.T) {
+	assert := testify.New(t)
+
+	tile := &Tile{}
+	tile.SubTileFlags[1*5+3] = NewSubTileFlags(0x01)
+
+	visited := make([][2]int, 0, 25)
+
+	tile.ForEachSubTile(func(col, row int, flags SubTileFlags) {
+		assert.Equal(col == 3 && row == 1, flags.BlockWalk, "sub-tile (%d, %d)", col, row)
+		visited = append(visited, [2]int{col, row})
+	})
+
+	assert.Len(visited, 25)
+	assert.Equal([2]int{0, 0}, visited[0])
+	assert.Equal([2]int{0, 1}, visited[1], "column-major order")
+	assert.Equal([2]int{4, 4}, visited[24])
+
+	calls := 0
+	errStop := errors.New("stop")
+
+	err := tile.ForEachSubTileE(func(col, row int, flags SubTileFlags) error {
+		calls++
+		if flags.BlockWalk {
+			return errStop
+		}
+
+		return nil
+	})
+
+	assert.ErrorIs(err, errStop)
+	assert.Equal(3*5+2, calls)
+}
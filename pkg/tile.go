@@ -2,9 +2,11 @@ package pkg
 
 import (
 	"errors"
+	"fmt"
 	"image"
 	"image/color"
 	"image/draw"
+	"sort"
 )
 
 const (
@@ -45,16 +47,13 @@ func (t *Tile) Image() image.Image {
 		return nil
 	}
 
-	tw, th := int(t.Width), int(t.Height)
-	if th < 0 {
-		th *= -1
-	}
+	tw, th := int(t.Width), t.WallHeight()
 
 	rect := image.Rect(0, 0, tw, th)
 	imgFloor, imgWall := image.NewRGBA(rect), image.NewRGBA(rect)
 	imgFloor.Pix, imgWall.Pix = floorPix, wallPix
 
-	return compositeImage(imgFloor, imgWall)
+	return t.Composite(imgFloor, imgWall)
 }
 
 func (t *Tile) WallImage() image.Image {
@@ -63,10 +62,7 @@ func (t *Tile) WallImage() image.Image {
 		return nil
 	}
 
-	tw, th := int(t.Width), int(t.Height)
-	if th < 0 {
-		th *= -1
-	}
+	tw, th := int(t.Width), t.WallHeight()
 
 	rect := image.Rect(0, 0, tw, th)
 	imgWall := image.NewRGBA(rect)
@@ -81,10 +77,7 @@ func (t *Tile) FloorImage() image.Image {
 		return nil
 	}
 
-	tw, th := int(t.Width), int(t.Height)
-	if th < 0 {
-		th *= -1
-	}
+	tw, th := int(t.Width), t.WallHeight()
 
 	rect := image.Rect(0, 0, tw, th)
 	imgFloor := image.NewRGBA(rect)
@@ -93,16 +86,243 @@ func (t *Tile) FloorImage() image.Image {
 	return imgFloor
 }
 
-// Composite creates a new image by drawing src on top of dst.
-func compositeImage(dst, src image.Image) *image.RGBA {
-	// Initialize a blank RGBA image with the size of dst
-	compositeImg := image.NewRGBA(dst.Bounds())
+// WallHeight returns the absolute height of the tile. Wall tiles have a
+// negative Height, the value returned here is always non-negative and can be
+// used directly to allocate pixel buffers.
+func (t *Tile) WallHeight() int {
+	return int(AbsInt32(t.Height))
+}
+
+// IsNegativeHeight returns true if the tile's Height is negative, which is
+// the case for wall tiles.
+func (t *Tile) IsNegativeHeight() bool {
+	return t.Height < 0
+}
+
+// Dimensions returns the width and the absolute height of the tile
+func (t *Tile) Dimensions() (width, height int) {
+	return int(t.Width), t.WallHeight()
+}
+
+// RawHeight returns the signed Height of the tile, as stored in the file. The
+// sign distinguishes wall tile geometry (negative) from floor tile geometry.
+func (t *Tile) RawHeight() int32 {
+	return t.Height
+}
 
-	// Draw dst onto the new image
-	draw.Draw(compositeImg, compositeImg.Bounds(), dst, dst.Bounds().Min, draw.Src)
+// ParentDT1 returns the DT1 the tile belongs to, or nil if it does not belong
+// to a DT1.
+func (t *Tile) ParentDT1() *DT1 {
+	return t.dt1
+}
 
-	// Draw src on top of the new image
-	draw.Draw(compositeImg, src.Bounds(), src, src.Bounds().Min, draw.Over)
+// NumBlocks returns the number of blocks in the tile
+func (t *Tile) NumBlocks() int {
+	return len(t.Blocks)
+}
+
+// BlockCount is an alias of NumBlocks and returns the number of blocks in the
+// tile.
+//
+// Deprecated: Use NumBlocks instead.
+func (t *Tile) BlockCount() int {
+	return t.NumBlocks()
+}
+
+// RoofOffset returns the number of pixels the tile is raised by when it is
+// drawn as a roof, which is the RoofHeight of roof tiles. Other tile types are
+// not raised, so 0 is returned for them.
+func (t *Tile) RoofOffset() int {
+	if t.Type != TileTypeRoof {
+		return 0
+	}
+
+	return int(t.RoofHeight)
+}
+
+// ShiftBlocks moves all blocks of the tile by (dx, dy), see
+// Block.ShiftPosition. If any block would no longer fit within the tile, an
+// error is returned and no block is moved.
+func (t *Tile) ShiftBlocks(dx, dy int16) error {
+	if err := t.checkShift(t.Blocks, dx, dy); err != nil {
+		return err
+	}
+
+	// the blocks are moved together, moving them one at a time with
+	// ShiftPosition could fail on an intermediate layout
+	for _, block := range t.Blocks {
+		block.X += dx
+		block.Y += dy
+		block.stale = true
+	}
+
+	return nil
+}
+
+// checkShift verifies that every block of the tile can still be decoded within
+// the tile after the given blocks are moved by (dx, dy), using the same rule as
+// Block.ValidateOffsets. Moving blocks can change the vertical offset of the
+// tile, so all blocks are checked, not only the moved ones. The blocks are
+// moved back before returning.
+func (t *Tile) checkShift(moved []*Block, dx, dy int16) error {
+	for _, block := range moved {
+		block.X += dx
+		block.Y += dy
+	}
+
+	defer func() {
+		for _, block := range moved {
+			block.X -= dx
+			block.Y -= dy
+		}
+	}()
+
+	tw, th := t.Width, AbsInt32(t.Height)
+
+	for _, block := range t.Blocks {
+		if err := block.ValidateOffsets(tw, th); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SplitBlocks splits the blocks of the tile into the isometric (floor) blocks
+// and the RLE (wall) blocks.
+func (t *Tile) SplitBlocks() (floor, wall []*Block) {
+	for _, block := range t.Blocks {
+		switch block.Format() {
+		case BlockFormatIsometric:
+			floor = append(floor, block)
+		case BlockFormatRLE:
+			wall = append(wall, block)
+		}
+	}
+
+	return floor, wall
+}
+
+// ForEachBlock calls fn for each block of the tile, in order, with the index
+// of the block. Iteration stops early if fn returns false.
+func (t *Tile) ForEachBlock(fn func(i int, b *Block) bool) {
+	for idx, block := range t.Blocks {
+		if !fn(idx, block) {
+			return
+		}
+	}
+}
+
+// BlockAt returns the block occupying the grid cell (gridX, gridY). If more
+// than one block occupies the cell, the first one is returned. An error is
+// returned if the cell is not occupied.
+func (t *Tile) BlockAt(gridX, gridY byte) (*Block, error) {
+	for _, block := range t.Blocks {
+		if block.GridX == gridX && block.GridY == gridY {
+			return block, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no block at grid cell (%d, %d)", gridX, gridY)
+}
+
+// BlocksByGridRow returns the blocks in row gridY of the 5x5 grid, ordered by
+// GridX.
+func (t *Tile) BlocksByGridRow(gridY byte) []*Block {
+	row := make([]*Block, 0)
+
+	for _, block := range t.Blocks {
+		if block.GridY == gridY {
+			row = append(row, block)
+		}
+	}
+
+	sort.SliceStable(row, func(i, j int) bool {
+		return row[i].GridX < row[j].GridX
+	})
+
+	return row
+}
+
+// clone returns a copy of the tile and its blocks which belongs to the given
+// DT1. The pixel and encoded data buffers are shared with the original.
+func (t *Tile) clone(parent *DT1) *Tile {
+	clone := *t
+	clone.dt1 = parent
+	clone.Blocks = make([]*Block, len(t.Blocks))
+
+	for idx, block := range t.Blocks {
+		blockClone := *block
+		blockClone.tile = &clone
+		clone.Blocks[idx] = &blockClone
+	}
+
+	return &clone
+}
+
+// OuterBounds returns the rectangle, in tile-local coordinates, which covers
+// all of the tile's blocks. For wall tiles with a negative height this can
+// extend beyond image.Rect(0, 0, t.Width, t.Height).
+func (t *Tile) OuterBounds() image.Rectangle {
+	var bounds image.Rectangle
+
+	for idx, block := range t.Blocks {
+		w, h := block.Size()
+		x, y := int(block.X), int(block.Y)
+		blockBounds := image.Rect(x, y, x+w, y+h)
+
+		if idx == 0 {
+			bounds = blockBounds
+			continue
+		}
+
+		bounds = bounds.Union(blockBounds)
+	}
+
+	return bounds
+}
+
+// IsometricBounds returns the rectangle, in the same tile-local coordinates as
+// OuterBounds, which encloses the tile's isometric diamond. The diamond starts
+// at the block origin (0, 0), spans the width of the tile and is half as tall,
+// so it is 160x80 for a standard tile. Wall tiles are drawn above it, at
+// negative Y.
+func (t *Tile) IsometricBounds() image.Rectangle {
+	width := int(t.Width)
+
+	return image.Rect(0, 0, width, width/2)
+}
+
+// CompositeWithBackground renders the tile on top of a solid background color,
+// so that transparent pixels are filled with bg instead of being transparent.
+func (t *Tile) CompositeWithBackground(bg color.Color) image.Image {
+	img := t.Image()
+	if img == nil {
+		return nil
+	}
+
+	background := image.NewUniform(bg)
+	composite := image.NewRGBA(img.Bounds())
+
+	draw.Draw(composite, composite.Bounds(), background, image.Point{}, draw.Src)
+	draw.Draw(composite, composite.Bounds(), img, img.Bounds().Min, draw.Over)
+
+	return composite
+}
+
+// Composite creates a new image by drawing the layers on top of each other,
+// from the bottom to the top, with the first layer at the bottom. The result
+// has the bounds of the bottom layer. Returns nil if no layers are given.
+func (t *Tile) Composite(layers ...image.Image) image.Image {
+	if len(layers) == 0 {
+		return nil
+	}
+
+	compositeImg := image.NewRGBA(layers[0].Bounds())
+
+	for _, layer := range layers {
+		draw.Draw(compositeImg, layer.Bounds(), layer, layer.Bounds().Min, draw.Over)
+	}
 
 	return compositeImg
 }
@@ -137,18 +357,9 @@ func (t *Tile) makePixelBuffer() (floorBuf, wallBuf []byte) {
 		bpp // bytes per pixel
 	)
 
-	tw, th := int(t.Width), int(t.Height)
-	if th < 0 {
-		th *= -1
-	}
-
-	var tileYMinimum int32
+	tw, th := int(t.Width), t.WallHeight()
 
-	for _, block := range t.Blocks {
-		tileYMinimum = MinInt32(tileYMinimum, int32(block.Y))
-	}
-
-	tileYOffset := AbsInt32(tileYMinimum)
+	tileYOffset := t.yOffset()
 
 	floor := make([]byte, tw*th) // indices into palette
 	wall := make([]byte, tw*th)  // indices into palette
@@ -215,6 +426,85 @@ func (t *Tile) makePixelBuffer() (floorBuf, wallBuf []byte) {
 	return floorBuf, wallBuf
 }
 
+// PaletteIndices returns a new buffer with the merged floor and wall palette
+// indices of the tile, where non-transparent wall pixels win over floor
+// pixels. The buffer has a length of Width * WallHeight, and can be converted
+// to RGBA with ImgIndexToRGBA.
+func (t *Tile) PaletteIndices() []byte {
+	tw, th := int(t.Width), t.WallHeight()
+
+	floor := make([]byte, tw*th)
+	wall := make([]byte, tw*th)
+
+	decodeTileGfxData(t.Blocks, &floor, &wall, t.yOffset(), t.Width)
+
+	for idx, wallVal := range wall {
+		if wallVal != 0 {
+			floor[idx] = wallVal
+		}
+	}
+
+	return floor
+}
+
+// NonTransparentBounds returns the smallest rectangle containing every
+// non-transparent pixel of the composited tile. The rectangle is empty if the
+// tile has no visible pixels.
+func (t *Tile) NonTransparentBounds() image.Rectangle {
+	width := int(t.Width)
+	if width <= 0 {
+		return image.Rectangle{}
+	}
+
+	var bounds image.Rectangle
+
+	for idx, palIdx := range t.PaletteIndices() {
+		if palIdx == 0 {
+			continue
+		}
+
+		x, y := idx%width, idx/width
+		bounds = bounds.Union(image.Rect(x, y, x+1, y+1))
+	}
+
+	return bounds
+}
+
+// CropToContent renders the tile and returns a new image containing only the
+// area within NonTransparentBounds, with its origin at (0, 0). An error is
+// returned if the tile is entirely transparent.
+func (t *Tile) CropToContent() (*image.RGBA, error) {
+	bounds := t.NonTransparentBounds()
+	if bounds.Empty() {
+		return nil, errors.New("tile has no visible pixels")
+	}
+
+	cropped := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(cropped, cropped.Bounds(), t.canvas(), bounds.Min, draw.Src)
+
+	return cropped, nil
+}
+
+// PixelIterator returns a function which yields the pixels of the composited
+// tile, as palette indices, one at a time in row-major order. Once all pixels
+// have been yielded, ok is false.
+func (t *Tile) PixelIterator() func() (x, y int, index uint8, ok bool) {
+	pixels := t.PaletteIndices()
+	width := int(t.Width)
+	cursor := 0
+
+	return func() (x, y int, index uint8, ok bool) {
+		if width <= 0 || cursor >= len(pixels) {
+			return 0, 0, 0, false
+		}
+
+		x, y, index = cursor%width, cursor/width, pixels[cursor]
+		cursor++
+
+		return x, y, index, true
+	}
+}
+
 // we want to render the isometric (floor) and rle (wall) pixel buffers separately
 func decodeTileGfxData(blocks []*Block, floorPixBuf, wallPixBuf *[]byte, tileYOffset, tileWidth int32) {
 	for i := range blocks {
@@ -231,9 +521,21 @@ const (
 	blockDataLength = 256
 )
 
-// DecodeTileGfxData decodes tile graphics data for a slice of dt1 blocks
+// DecodeTileGfxData decodes tile graphics data for a slice of dt1 blocks.
+// Blocks which would be written outside of the pixel buffer, or whose encoded
+// data is truncated, are skipped.
 func DecodeTileGfxData(blocks []*Block, pixels *[]byte, tileYOffset, tileWidth int32) {
+	if tileWidth <= 0 {
+		return
+	}
+
+	tileHeight := int32(len(*pixels)) / tileWidth
+
 	for _, block := range blocks {
+		if err := block.validateOffsets(tileWidth, tileHeight, tileYOffset); err != nil {
+			continue
+		}
+
 		if block.Format() == BlockFormatIsometric {
 			// 3D isometric decoding
 			xjump := []int32{14, 12, 10, 8, 6, 4, 2, 0, 2, 4, 6, 8, 10, 12, 14}
@@ -337,3 +639,11 @@ func AbsInt32(a int32) int32 {
 
 	return a
 }
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
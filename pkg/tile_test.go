@@ -0,0 +1,286 @@
+package pkg
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+func TestTileCropToContent(t *testing.T) {
+	assert := testify.New(t)
+
+	tile := testEncodableDT1().Tiles[0]
+
+	assert.Equal(image.Rect(0, 0, 32, 47), tile.NonTransparentBounds())
+
+	cropped, err := tile.CropToContent()
+	assert.NoError(err)
+	assert.Equal(image.Rect(0, 0, 32, 47), cropped.Bounds())
+	assert.NotZero(cropped.RGBAAt(17, 0).A)
+	assert.Zero(cropped.RGBAAt(16, 0).A)
+
+	empty := &Tile{Width: 160, Height: -80}
+
+	_, err = empty.CropToContent()
+	assert.Error(err)
+}
+
+func TestTileShiftBlocks(t *testing.T) {
+	assert := testify.New(t)
+
+	tile := testEncodableDT1().Tiles[0]
+	before := tile.PaletteIndices()
+
+	assert.NoError(tile.ShiftBlocks(0, 0))
+	assert.NoError(tile.ShiftBlocks(8, 0))
+	assert.Equal(int16(24), tile.Blocks[0].X)
+	assert.Equal(int16(8), tile.Blocks[1].X)
+
+	after := tile.PaletteIndices()
+	width := int(tile.Width)
+
+	for idx, palIdx := range before {
+		x, y := idx%width, idx/width
+		if x+8 < width {
+			assert.Equal(palIdx, after[y*width+x+8])
+		}
+	}
+
+	// moving every block up keeps the tile offset relative to the blocks
+	assert.NoError(tile.ShiftBlocks(0, -4))
+	assert.Equal(int16(-36), tile.Blocks[0].Y)
+	assert.Equal(after, tile.PaletteIndices())
+
+	assert.Error(tile.ShiftBlocks(200, 0))
+	assert.Equal(int16(24), tile.Blocks[0].X)
+
+	d, err := FromFile("testdata/tiles.dt1")
+	assert.NoError(err)
+
+	for _, tile := range d.Tiles {
+		assert.NoError(tile.ShiftBlocks(0, 0))
+	}
+}
+
+func TestTileOutOfBoundsBlock(t *testing.T) {
+	assert := testify.New(t)
+
+	d := NewTestDT1(1, 2)
+	tile := d.Tiles[0]
+	tile.Blocks[1].Y = 100
+
+	assert.Error(d.DecodeAllGraphics())
+
+	assert.NotPanics(func() {
+		pixels := tile.PaletteIndices()
+		assert.Len(pixels, 160*80)
+		assert.NotZero(pixels[16])
+	})
+
+	assert.NotPanics(func() {
+		assert.NotNil(tile.Image())
+
+		_, err := d.TileAtlas(1)
+		assert.NoError(err)
+	})
+}
+
+func TestTileBlockCount(t *testing.T) {
+	assert := testify.New(t)
+
+	d := NewTestDT1(2, 0)
+	d.Tiles[1] = NewTestDT1(1, 7).Tiles[0]
+
+	for _, tile := range d.Tiles {
+		assert.Equal(len(tile.Blocks), tile.NumBlocks())
+		assert.Equal(tile.NumBlocks(), tile.BlockCount())
+	}
+
+	assert.Equal(7, d.MaxBlocksPerTile())
+}
+
+func TestTileCompositeWithBackground(t *testing.T) {
+	assert := testify.New(t)
+
+	tile := NewTestDT1(1, 1).Tiles[0]
+	blue := color.RGBA{B: 255, A: 255}
+
+	img := tile.CompositeWithBackground(blue)
+	assert.Equal(image.Rect(0, 0, 160, 80), img.Bounds())
+	assert.Equal(blue, img.At(0, 0), "transparent pixels show the background")
+	assert.Equal(color.RGBA{R: 1, G: 1, B: 1, A: 255}, img.At(14, 0))
+	assert.Equal(color.RGBA{R: 2, G: 2, B: 2, A: 255}, img.At(15, 0))
+}
+
+func TestTileOuterBounds(t *testing.T) {
+	assert := testify.New(t)
+
+	tile := testEncodableDT1().Tiles[0]
+	assert.Equal(image.Rect(0, -32, 32, 16), tile.OuterBounds())
+
+	tile.Blocks[0].X = 150
+	assert.Equal(image.Rect(0, -32, 153, 16), tile.OuterBounds())
+
+	tile.Blocks = nil
+	assert.True(tile.OuterBounds().Empty())
+}
+
+func TestTileWallHeight(t *testing.T) {
+	assert := testify.New(t)
+
+	tile := &Tile{Width: 160, Height: 80}
+	assert.Equal(80, tile.WallHeight())
+	assert.False(tile.IsNegativeHeight())
+
+	tile.Height = -96
+	assert.Equal(96, tile.WallHeight())
+	assert.True(tile.IsNegativeHeight())
+}
+
+func TestTilePixelIterator(t *testing.T) {
+	assert := testify.New(t)
+
+	tile := testEncodableDT1().Tiles[0]
+	pixels := make([]byte, 0, 160*80)
+
+	next := tile.PixelIterator()
+
+	for x, y, index, ok := next(); ok; x, y, index, ok = next() {
+		assert.Equal(len(pixels)%160, x)
+		assert.Equal(len(pixels)/160, y)
+
+		pixels = append(pixels, index)
+	}
+
+	assert.Equal(tile.PaletteIndices(), pixels)
+	assert.Equal(uint8(7), pixels[17])
+	assert.Equal(uint8(8), pixels[18])
+
+	_, _, _, ok := next()
+	assert.False(ok, "an exhausted iterator stays exhausted")
+}
+
+func TestTileComposite(t *testing.T) {
+	assert := testify.New(t)
+
+	tile := &Tile{}
+	red, blue := color.RGBA{R: 255, A: 255}, color.RGBA{B: 255, A: 255}
+
+	bottom := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	draw.Draw(bottom, bottom.Bounds(), image.NewUniform(red), image.Point{}, draw.Src)
+
+	top := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	top.SetRGBA(1, 1, blue)
+
+	img := tile.Composite(bottom, top)
+	assert.Equal(image.Rect(0, 0, 2, 2), img.Bounds())
+	assert.Equal(red, img.At(0, 0), "transparent pixels of the top layer")
+	assert.Equal(blue, img.At(1, 1))
+
+	reversed := tile.Composite(top, bottom)
+	assert.Equal(image.Rect(0, 0, 4, 4), reversed.Bounds())
+	assert.Equal(red, reversed.At(1, 1), "the last layer is drawn on top")
+	assert.Equal(color.RGBA{}, reversed.At(3, 3))
+	assert.Nil(tile.Composite())
+}
+
+func TestTilePaletteIndices(t *testing.T) {
+	assert := testify.New(t)
+
+	tile := testEncodableDT1().Tiles[0]
+
+	pixels := tile.PaletteIndices()
+	assert.Len(pixels, 160*80)
+	assert.Equal([]byte{0, 7, 8, 0}, pixels[16:20])
+	assert.Equal(uint8(5), pixels[32*160+16])
+
+	// wall pixels win over the floor pixels they overlap
+	rle := tile.Blocks[0]
+	rle.X, rle.Y = 0, 0
+	rle.EncodedData = []byte{14, 2, 7, 8, 0, 0}
+
+	pixels = tile.PaletteIndices()
+	assert.Equal([]byte{0, 7, 8, 5, 5, 0}, pixels[13:19])
+}
+
+func TestTileNumBlocks(t *testing.T) {
+	assert := testify.New(t)
+
+	tile := NewTestDT1(1, 4).Tiles[0]
+	assert.Equal(4, tile.NumBlocks())
+
+	tile.Blocks = tile.Blocks[:1]
+	assert.Equal(1, tile.NumBlocks())
+
+	assert.Zero((&Tile{}).NumBlocks())
+}
+
+func TestTileBlockAt(t *testing.T) {
+	assert := testify.New(t)
+
+	tile := NewTestDT1(1, 7).Tiles[0]
+
+	block, err := tile.BlockAt(1, 1)
+	assert.NoError(err)
+	assert.Same(tile.Blocks[6], block)
+
+	// the first block wins if a cell is occupied twice
+	tile.Blocks[5].GridX, tile.Blocks[5].GridY = 1, 1
+
+	block, err = tile.BlockAt(1, 1)
+	assert.NoError(err)
+	assert.Same(tile.Blocks[5], block)
+
+	block, err = tile.BlockAt(4, 4)
+	assert.Error(err)
+	assert.Nil(block)
+}
+
+func TestTileBlocksByGridRow(t *testing.T) {
+	assert := testify.New(t)
+
+	tile := NewTestDT1(1, 8).Tiles[0]
+
+	// put the blocks of the second row in reverse order
+	tile.Blocks[5], tile.Blocks[7] = tile.Blocks[7], tile.Blocks[5]
+
+	row := tile.BlocksByGridRow(1)
+	if assert.Len(row, 3) {
+		for idx, block := range row {
+			assert.Equal(byte(idx), block.GridX)
+			assert.Equal(byte(1), block.GridY)
+		}
+	}
+
+	assert.Len(tile.BlocksByGridRow(0), 5)
+	assert.Empty(tile.BlocksByGridRow(4))
+}
+
+func TestTileRoofOffset(t *testing.T) {
+	assert := testify.New(t)
+
+	tile := &Tile{Type: TileTypeRoof, RoofHeight: 48}
+	assert.Equal(48, tile.RoofOffset())
+
+	tile.Type = TileTypeFloor
+	assert.Zero(tile.RoofOffset(), "only roofs are raised")
+}
+
+func TestTileForEachBlock(t *testing.T) {
+	assert := testify.New(t)
+
+	tile := NewTestDT1(1, 4).Tiles[0]
+	visited := make([]int, 0)
+
+	tile.ForEachBlock(func(i int, b *Block) bool {
+		assert.Same(tile.Blocks[i], b)
+		visited = append(visited, i)
+
+		return i < 2
+	})
+
+	assert.Equal([]int{0, 1, 2}, visited, "iteration stops once fn returns false")
+}
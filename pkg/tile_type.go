@@ -0,0 +1,93 @@
+package pkg
+
+// These are the known values of Tile.Type, which determine how a tile is
+// oriented and rendered.
+const (
+	TileTypeFloor int32 = iota
+	TileTypeLeftWall
+	TileTypeRightWall
+	TileTypeRightNorthCornerWall
+	TileTypeLeftNorthCornerWall
+	TileTypeLeftEndWall
+	TileTypeRightEndWall
+	TileTypeSouthCornerWall
+	TileTypeLeftWallDoor
+	TileTypeRightWallDoor
+	TileTypeSpecial1
+	TileTypeSpecial2
+	TileTypePillar
+	TileTypeShadow
+	TileTypeTree
+	TileTypeRoof
+	TileTypeLowerLeftWall
+	TileTypeLowerRightWall
+	TileTypeLowerNorthCornerWall
+	TileTypeLowerSouthCornerWall
+)
+
+// IsKnownTileType returns true if the value is one of the known tile types
+func IsKnownTileType(tileType int32) bool {
+	return tileType >= TileTypeFloor && tileType <= TileTypeLowerSouthCornerWall
+}
+
+// IsFloor returns true if the tile is a floor tile
+func (t *Tile) IsFloor() bool {
+	return t.Type == TileTypeFloor
+}
+
+// IsRoof returns true if the tile is a roof tile
+func (t *Tile) IsRoof() bool {
+	return t.Type == TileTypeRoof
+}
+
+// IsWall returns true if the tile is an upper or a lower wall
+func (t *Tile) IsWall() bool {
+	return t.IsUpperWall() || t.IsLowerWall()
+}
+
+// IsUpperWall returns true if the tile is drawn as a wall above the floor
+func (t *Tile) IsUpperWall() bool {
+	switch t.Type {
+	case TileTypeLeftWall,
+		TileTypeRightWall,
+		TileTypeRightNorthCornerWall,
+		TileTypeLeftNorthCornerWall,
+		TileTypeLeftEndWall,
+		TileTypeRightEndWall,
+		TileTypeSouthCornerWall,
+		TileTypeLeftWallDoor,
+		TileTypeRightWallDoor,
+		TileTypePillar,
+		TileTypeTree:
+		return true
+	}
+
+	return false
+}
+
+// IsLowerWall returns true if the tile is one of the lower wall types
+func (t *Tile) IsLowerWall() bool {
+	return t.Type >= TileTypeLowerLeftWall && t.Type <= TileTypeLowerSouthCornerWall
+}
+
+// IsLeftWall returns true if the wall is painted on the left side of the
+// isometric cell
+func (t *Tile) IsLeftWall() bool {
+	switch t.Type {
+	case TileTypeLeftWall, TileTypeLeftWallDoor, TileTypeLowerLeftWall:
+		return true
+	}
+
+	return false
+}
+
+// IsRightWall returns true if the wall is painted on the right side of the
+// isometric cell
+func (t *Tile) IsRightWall() bool {
+	switch t.Type {
+	case TileTypeRightWall, TileTypeRightWallDoor, TileTypeLowerRightWall:
+		return true
+	}
+
+	return false
+}
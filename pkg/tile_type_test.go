@@ -0,0 +1,49 @@
+package pkg
+
+import (
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+func TestTileTypeChecks(t *testing.T) {
+	assert := testify.New(t)
+
+	tests := []struct {
+		tileType                        int32
+		floor, wall, upper, lower, roof bool
+	}{
+		{TileTypeFloor, true, false, false, false, false},
+		{TileTypeLeftWall, false, true, true, false, false},
+		{TileTypeRightWall, false, true, true, false, false},
+		{TileTypeRightNorthCornerWall, false, true, true, false, false},
+		{TileTypeLeftNorthCornerWall, false, true, true, false, false},
+		{TileTypeLeftEndWall, false, true, true, false, false},
+		{TileTypeRightEndWall, false, true, true, false, false},
+		{TileTypeSouthCornerWall, false, true, true, false, false},
+		{TileTypeLeftWallDoor, false, true, true, false, false},
+		{TileTypeRightWallDoor, false, true, true, false, false},
+		{TileTypeSpecial1, false, false, false, false, false},
+		{TileTypeSpecial2, false, false, false, false, false},
+		{TileTypePillar, false, true, true, false, false},
+		{TileTypeShadow, false, false, false, false, false},
+		{TileTypeTree, false, true, true, false, false},
+		{TileTypeRoof, false, false, false, false, true},
+		{TileTypeLowerLeftWall, false, true, false, true, false},
+		{TileTypeLowerRightWall, false, true, false, true, false},
+		{TileTypeLowerNorthCornerWall, false, true, false, true, false},
+		{TileTypeLowerSouthCornerWall, false, true, false, true, false},
+	}
+
+	assert.Len(tests, int(TileTypeLowerSouthCornerWall)+1, "every tile type is covered")
+
+	for _, test := range tests {
+		tile := &Tile{Type: test.tileType}
+
+		assert.Equal(test.floor, tile.IsFloor(), "IsFloor, type %d", test.tileType)
+		assert.Equal(test.wall, tile.IsWall(), "IsWall, type %d", test.tileType)
+		assert.Equal(test.upper, tile.IsUpperWall(), "IsUpperWall, type %d", test.tileType)
+		assert.Equal(test.lower, tile.IsLowerWall(), "IsLowerWall, type %d", test.tileType)
+		assert.Equal(test.roof, tile.IsRoof(), "IsRoof, type %d", test.tileType)
+	}
+}
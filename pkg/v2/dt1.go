@@ -5,11 +5,47 @@ import (
 	"image/color"
 	"io"
 	"math"
+	"os"
 
 	"github.com/gravestench/bitstream"
 )
 
 func New(buffer io.Reader) (*DT1, error) {
+	return decode(buffer, nil)
+}
+
+// NewFromFile opens the file at the given path and decodes it with New
+func NewFromFile(path string) (*DT1, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening file: %v", err)
+	}
+
+	defer file.Close()
+
+	return New(file)
+}
+
+// NewStreaming decodes a DT1 like New, but sends the number of tiles decoded
+// so far to progress after each tile completes. Sends never block, so a
+// progress update is dropped if the receiver is not ready. The channel is
+// closed once decoding finishes. A nil channel disables progress reporting.
+func NewStreaming(r io.Reader, progress chan<- int) (*DT1, error) {
+	if progress == nil {
+		return decode(r, nil)
+	}
+
+	defer close(progress)
+
+	return decode(r, func(numDecoded int) {
+		select {
+		case progress <- numDecoded:
+		default:
+		}
+	})
+}
+
+func decode(buffer io.Reader, onTileDecoded func(numDecoded int)) (*DT1, error) {
 	d := &DT1{}
 
 	stream := bitstream.NewReader(buffer)
@@ -18,7 +54,7 @@ func New(buffer io.Reader) (*DT1, error) {
 		return nil, fmt.Errorf("decoding header: %v", err)
 	}
 
-	if err := d.decodeBody(stream); err != nil {
+	if err := d.decodeBody(stream, onTileDecoded); err != nil {
 		return nil, fmt.Errorf("decoding header: %v", err)
 	}
 
@@ -34,6 +70,11 @@ type DT1 struct {
 	palette color.Palette
 }
 
+// Version returns the version numbers read from the header
+func (d *DT1) Version() (v1, v2 int32) {
+	return d.header.V1, d.header.V2
+}
+
 func (d *DT1) Palette() color.Palette {
 	return d.palette
 }
@@ -101,12 +142,12 @@ func (d *DT1) decodeDT1Version(stream *bitstream.Reader) error {
 	return nil
 }
 
-func (d *DT1) decodeBody(stream *bitstream.Reader) error {
+func (d *DT1) decodeBody(stream *bitstream.Reader, onTileDecoded func(numDecoded int)) error {
 	if err := d.decodeTileHeaders(stream); err != nil {
 		return fmt.Errorf("decoding stage 1: %v", err)
 	}
 
-	if err := d.decodeTileBodies(stream); err != nil {
+	if err := d.decodeTileBodies(stream, onTileDecoded); err != nil {
 		return fmt.Errorf("decoding stage 2: %v", err)
 	}
 
@@ -140,7 +181,8 @@ func (d *DT1) decodeTileHeaders(stream *bitstream.Reader) error {
 	for tileIdx := range d.Tiles {
 		tile := &Tile{}
 
-		tile.Direction, _ = stream.Next(directionBytes).Bytes().AsInt32()
+		direction, _ := stream.Next(directionBytes).Bytes().AsInt32()
+		tile.Direction = Direction(direction)
 		tile.RoofHeight, _ = stream.Next(roofHeightBytes).Bytes().AsInt16()
 
 		materials, _ := stream.Next(materialsBytes).Bytes().AsUInt16()
@@ -180,7 +222,7 @@ func (d *DT1) decodeTileHeaders(stream *bitstream.Reader) error {
 	return nil
 }
 
-func (d *DT1) decodeTileBodies(stream *bitstream.Reader) error {
+func (d *DT1) decodeTileBodies(stream *bitstream.Reader, onTileDecoded func(numDecoded int)) error {
 	for idx := range d.Tiles {
 		if err := d.Tiles[idx].decodeBlockHeaders(stream); err != nil {
 			return fmt.Errorf("decoding black headers: %v", err)
@@ -189,6 +231,10 @@ func (d *DT1) decodeTileBodies(stream *bitstream.Reader) error {
 		if err := d.Tiles[idx].decodeBlockBodies(stream); err != nil {
 			return fmt.Errorf("decoding block bodies: %v", err)
 		}
+
+		if onTileDecoded != nil {
+			onTileDecoded(idx + 1)
+		}
 	}
 
 	return nil
@@ -0,0 +1,100 @@
+package v2
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"os"
+	"testing"
+
+	testify "github.com/stretchr/testify/assert"
+)
+
+// testFile is the DT1 file in the testdata of the v1 package
+const testFile = "../testdata/tiles.dt1"
+
+func TestNewStreaming(t *testing.T) {
+	assert := testify.New(t)
+
+	data, err := os.ReadFile(testFile)
+	if !assert.NoError(err) {
+		return
+	}
+
+	expected, err := New(bytes.NewReader(data))
+	if !assert.NoError(err) {
+		return
+	}
+
+	progress := make(chan int, len(expected.Tiles))
+
+	d, err := NewStreaming(bytes.NewReader(data), progress)
+	assert.NoError(err)
+	assert.Len(d.Tiles, len(expected.Tiles))
+
+	updates := make([]int, 0)
+	for numDecoded := range progress {
+		updates = append(updates, numDecoded)
+	}
+
+	if assert.Len(updates, len(expected.Tiles)) {
+		assert.Equal(len(expected.Tiles), updates[len(updates)-1])
+	}
+}
+
+func TestNewStreamingWithoutProgress(t *testing.T) {
+	assert := testify.New(t)
+
+	data, err := os.ReadFile(testFile)
+	if !assert.NoError(err) {
+		return
+	}
+
+	assert.NotPanics(func() {
+		d, err := NewStreaming(bytes.NewReader(data), nil)
+		assert.NoError(err)
+		assert.NotEmpty(d.Tiles)
+	})
+}
+
+func TestTileWithPalette(t *testing.T) {
+	assert := testify.New(t)
+
+	original := color.Palette{color.RGBA{}, color.RGBA{R: 255, A: 255}}
+	preview := color.Palette{color.RGBA{}, color.RGBA{B: 255, A: 255}}
+
+	d := &DT1{Tiles: []*Tile{{
+		Width:  2,
+		Height: 1,
+		Blocks: []*Block{{PixelData: []byte{0, 1}, image: image.NewRGBA(image.Rect(0, 0, 2, 1))}},
+	}}}
+	d.SetPalette(original)
+
+	tile := d.Tiles[0]
+	clone := tile.WithPalette(preview)
+
+	assert.NotSame(tile, clone)
+	assert.NotSame(tile.Blocks[0], clone.Blocks[0])
+	assert.Equal(preview, clone.ColorModel())
+	assert.Equal(preview[1], clone.Blocks[0].At(1, 0))
+
+	assert.Equal(original, tile.ColorModel(), "the original tile must not change")
+	assert.Equal(original[1], tile.Blocks[0].At(1, 0))
+	assert.Equal(original, d.Palette())
+}
+
+func TestVersion(t *testing.T) {
+	assert := testify.New(t)
+
+	data, err := os.ReadFile(testFile)
+	if !assert.NoError(err) {
+		return
+	}
+
+	d, err := New(bytes.NewReader(data))
+	assert.NoError(err)
+
+	v1, v2 := d.Version()
+	assert.Equal(int32(7), v1)
+	assert.Equal(int32(6), v2)
+}
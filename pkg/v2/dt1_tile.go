@@ -9,7 +9,7 @@ import (
 
 // Tile is a representation of a map tile
 type Tile struct {
-	Direction          int32
+	Direction          Direction
 	RoofHeight         int16
 	MaterialFlags      MaterialFlags
 	Height             int32
@@ -128,3 +128,20 @@ func (t *Tile) At(x, y int) color.Color {
 	}
 	return color.RGBA{} // default color (transparent black)
 }
+
+// WithPalette returns a clone of the tile which uses the given palette. The
+// clone's blocks are cloned as well and receive the same palette, so the
+// original tile (and its parent DT1) is left untouched.
+func (t *Tile) WithPalette(p color.Palette) *Tile {
+	clone := *t
+	clone.palette = p
+	clone.Blocks = make([]*Block, len(t.Blocks))
+
+	for idx, block := range t.Blocks {
+		blockClone := *block
+		blockClone.palette = p
+		clone.Blocks[idx] = &blockClone
+	}
+
+	return &clone
+}
@@ -0,0 +1,60 @@
+package v2
+
+import "fmt"
+
+// Direction is the orientation of a tile, as stored in the tile header.
+//
+// The valid values are the eight compass directions, starting at North
+// and going clockwise:
+//
+//	0 = North
+//	1 = NorthEast
+//	2 = East
+//	3 = SouthEast
+//	4 = South
+//	5 = SouthWest
+//	6 = West
+//	7 = NorthWest
+//
+// Any other value is preserved as-is, but is not one of the known directions.
+type Direction int32
+
+const (
+	DirectionNorth Direction = iota
+	DirectionNorthEast
+	DirectionEast
+	DirectionSouthEast
+	DirectionSouth
+	DirectionSouthWest
+	DirectionWest
+	DirectionNorthWest
+)
+
+// String returns the name of the direction
+func (d Direction) String() string {
+	switch d {
+	case DirectionNorth:
+		return "North"
+	case DirectionNorthEast:
+		return "NorthEast"
+	case DirectionEast:
+		return "East"
+	case DirectionSouthEast:
+		return "SouthEast"
+	case DirectionSouth:
+		return "South"
+	case DirectionSouthWest:
+		return "SouthWest"
+	case DirectionWest:
+		return "West"
+	case DirectionNorthWest:
+		return "NorthWest"
+	}
+
+	return fmt.Sprintf("Direction(%d)", int32(d))
+}
+
+// IsValid returns true if the direction is one of the known directions
+func (d Direction) IsValid() bool {
+	return d >= DirectionNorth && d <= DirectionNorthWest
+}